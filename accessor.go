@@ -0,0 +1,49 @@
+package protect
+
+import (
+	"reflect"
+)
+
+// accessorKey is the key of accessors registered with RegisterAccessor.
+type accessorKey struct {
+	src reflect.Type
+	dst reflect.Type
+}
+
+// RegisterAccessor registers accessor to p to read values of type S as values of type D,
+// so that types exposing data only through methods (e.g. GetName() of protobuf messages
+// or encapsulated domain types) can be copied into tagged D with Copy:
+//
+//	protect.RegisterAccessor(protect.DefaultProtector, func(src *pb.User) UserDTO {
+//		return UserDTO{ID: src.GetId(), Name: src.GetName()}
+//	})
+//	err := protect.Copy("update", pbUser, &dto)
+//
+// Copy calls accessor with src of type S (or a pointer to S) when dst is a pointer to D,
+// and copies the returned value to dst with protection rules of D.
+// Registering an accessor for the same S and D replaces the previous one.
+func RegisterAccessor[S, D any](p *Protector, accessor func(src S) D) {
+	key := accessorKey{
+		src: reflect.TypeOf((*S)(nil)).Elem(),
+		dst: reflect.TypeOf((*D)(nil)).Elem(),
+	}
+	p.accessors.Store(key, func(src reflect.Value) reflect.Value {
+		return reflect.ValueOf(accessor(src.Interface().(S)))
+	})
+}
+
+// access reads src as a value of dst type with the accessor registered for them.
+// Accessors for the type src points to are also used.
+// It returns false if no accessor is registered.
+func (p *Protector) access(src reflect.Value, dst reflect.Type) (reflect.Value, bool) {
+	for src.Type() != dst {
+		if accessor, ok := p.accessors.Load(accessorKey{src: src.Type(), dst: dst}); ok {
+			return accessor.(func(reflect.Value) reflect.Value)(src), true
+		}
+		if src.Kind() != reflect.Ptr || src.IsNil() {
+			break
+		}
+		src = src.Elem()
+	}
+	return reflect.Value{}, false
+}
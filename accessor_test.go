@@ -0,0 +1,52 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// encapsulatedUser exposes its data only through methods.
+type encapsulatedUser struct {
+	id   string
+	name string
+}
+
+func (u *encapsulatedUser) GetID() string   { return u.id }
+func (u *encapsulatedUser) GetName() string { return u.name }
+
+type UserDTO struct {
+	ID   string `protectfor:"update"`
+	Name string
+}
+
+func TestRegisterAccessor(t *testing.T) {
+	p := NewProtector("protectfor", "protectopt")
+	RegisterAccessor(p, func(src *encapsulatedUser) UserDTO {
+		return UserDTO{ID: src.GetID(), Name: src.GetName()}
+	})
+
+	src := &encapsulatedUser{id: "new", name: "New"}
+	dst := UserDTO{ID: "existing", Name: "Old"}
+	err := p.Copy("update", src, &dst)
+	assert.NoError(t, err)
+	assert.Equal(t, UserDTO{ID: "existing", Name: "New"}, dst)
+
+	t.Run("accessors for the pointed type", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		RegisterAccessor(p, func(src encapsulatedUser) UserDTO {
+			return UserDTO{ID: src.GetID(), Name: src.GetName()}
+		})
+
+		var dst UserDTO
+		err := p.Copy("create", src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, UserDTO{ID: "new", Name: "New"}, dst)
+	})
+
+	t.Run("no accessors", func(t *testing.T) {
+		var dst UserDTO
+		err := DefaultProtector.Copy("update", src, &dst)
+		assert.Error(t, err)
+	})
+}
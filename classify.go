@@ -0,0 +1,187 @@
+package protect
+
+import (
+	"reflect"
+	"strings"
+)
+
+// classTagName is the tag name to classify fields, e.g. `protectclass:"pii"`.
+// Classes are comma-separated like protection tags.
+// A field classified as a class is also protected for the class as a tag,
+// so Copy("pii", src, dst) keeps all PII fields of dst.
+const classTagName = "protectclass"
+
+// protectForTagValue returns the protection tag value of the field.
+// Entries for promoted fields (e.g. "ID=update") are not included.
+func (p *Protector) protectForTagValue(field reflect.StructField) string {
+	return ownTagValue(field.Tag.Get(p.tagName))
+}
+
+// allowlistTagValue returns the tags the field is protected for in the allowlist mode,
+// e.g. "!create,!update" for `copyfor:"create,update"`, or "" if the allowlist mode is disabled.
+func (p *Protector) allowlistTagValue(field reflect.StructField) string {
+	allowlistTag, _ := p.allowlistTag.Load().(string)
+	if allowlistTag == "" {
+		return ""
+	}
+
+	// Fields are protected for all tags except the allowed ones
+	allowed := splitTagValue(field.Tag.Get(allowlistTag))
+	if len(allowed) == 0 {
+		return wildcardTag
+	}
+	var tagValue string
+	for _, tag := range allowed {
+		tagValue = joinTagValues(tagValue, negationPrefix+tag)
+	}
+	return tagValue
+}
+
+// promotedProtectionTagValue returns the tags the field is protected for,
+// including its classes and the tags derived from the allowlist tag,
+// applying the tags in promoted for the field from the embedding struct.
+// Tags from the embedding struct override the field's own protection tags,
+// or are joined with them as a separate group if SetCombineEmbeddedTags is enabled,
+// so that combining only ever adds protection.
+// Classes of the field and the allowlist tag always apply.
+// The tags derived from the allowlist tag are joined as a separate group,
+// so that their negations never unprotect fields protected by the protection tags.
+func (p *Protector) promotedProtectionTagValue(field reflect.StructField, promoted map[string]string) string {
+	tagValue := joinTagValues(p.promotedProtectForTagValue(field, promoted), field.Tag.Get(classTagName))
+	return joinTagGroups(tagValue, p.allowlistTagValue(field))
+}
+
+// promotedProtectForTagValue returns the protection tag value of the field like protectForTagValue,
+// applying the tags in promoted for the field from the embedding struct as promotedProtectionTagValue does.
+func (p *Protector) promotedProtectForTagValue(field reflect.StructField, promoted map[string]string) string {
+	tags, ok := promoted[field.Name]
+	if !ok {
+		return p.protectForTagValue(field)
+	}
+	if p.combineEmbeddedTags.Load() {
+		return joinTagGroups(p.protectForTagValue(field), tags)
+	}
+	return tags
+}
+
+// embeddedPromotedTagValues returns the tags for promoted fields attached by the field
+// if it is an embedded field, or nil otherwise.
+func (p *Protector) embeddedPromotedTagValues(field reflect.StructField) map[string]string {
+	if !field.Anonymous {
+		return nil
+	}
+	return promotedTagValues(field.Tag.Get(p.tagName))
+}
+
+// joinTagValues joins comma-separated tag values, skipping empty ones.
+func joinTagValues(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + "," + b
+}
+
+// tagGroupSeparator separates groups of tags in tag values evaluated independently by isProtected,
+// e.g. "!create;!update" is protected for every tag.
+const tagGroupSeparator = ";"
+
+// joinTagGroups joins tag values as independent groups, skipping empty ones.
+func joinTagGroups(a, b string) string {
+	if a == "" {
+		return b
+	}
+	if b == "" {
+		return a
+	}
+	return a + tagGroupSeparator + b
+}
+
+// ownTagValue returns the protection tag value without entries for promoted fields,
+// e.g. "create" for "create,ID=update".
+func ownTagValue(tagValue string) string {
+	if !strings.Contains(tagValue, "=") {
+		return tagValue
+	}
+	var own string
+	for _, entry := range strings.Split(tagValue, ",") {
+		if !strings.Contains(entry, "=") {
+			own = joinTagValues(own, strings.TrimSpace(entry))
+		}
+	}
+	return own
+}
+
+// promotedTagValues parses entries for promoted fields in the protection tag value of an embedded field,
+// e.g. `protectfor:"ID=create|update,CreatedAt=update"`, into tag values by field names.
+// Entries without tags (e.g. "ID=") are included with empty values to unprotect the fields.
+// Entries without "=" protect the embedded field as a whole and are not included.
+func promotedTagValues(tagValue string) map[string]string {
+	var promoted map[string]string
+	for _, entry := range strings.Split(tagValue, ",") {
+		name, tags, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		if promoted == nil {
+			promoted = make(map[string]string)
+		}
+		promoted[name] = joinTagValues(promoted[name], strings.ReplaceAll(tags, "|", ","))
+	}
+	return promoted
+}
+
+// ClassifiedFields returns the paths of fields classified as the class with `protectclass` tags
+// in the type of v.
+// See Protector.ClassifiedFields for details.
+func ClassifiedFields(class string, v interface{}) []string {
+	return DefaultProtector.ClassifiedFields(class, v)
+}
+
+// ClassifiedFields returns the paths of fields classified as the class with `protectclass` tags
+// in the type of v, in the format of ProtectedFields (e.g. "Customers[].Email").
+// Unlike ProtectedFields, fields in classified fields are also listed,
+// giving a complete inventory of the class (e.g. all PII fields) in the type graph.
+// Fields in primitive structs are not listed,
+// and fields of recursive types are listed only at their first occurrence.
+func (p *Protector) ClassifiedFields(class string, v interface{}) []string {
+	if v == nil || class == "" {
+		return nil
+	}
+
+	var fields []string
+	p.collectClassifiedFields(class, reflect.TypeOf(v), "", map[reflect.Type]bool{}, &fields)
+	return fields
+}
+
+// collectClassifiedFields collects paths of fields in t classified as the class into fields.
+func (p *Protector) collectClassifiedFields(class string, t reflect.Type, path string, visiting map[reflect.Type]bool, fields *[]string) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		p.collectClassifiedFields(class, t.Elem(), path, visiting, fields)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		p.collectClassifiedFields(class, t.Elem(), joinPath(path, "[]"), visiting, fields)
+	case reflect.Struct:
+		if p.IsPrimitiveStruct(t) || visiting[t] {
+			return
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldPath := joinPath(path, field.Name)
+			if isProtected(field.Tag.Get(classTagName), class) {
+				*fields = append(*fields, fieldPath)
+			}
+
+			p.collectClassifiedFields(class, field.Type, fieldPath, visiting, fields)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ClassifiedContact struct {
+	Email string `protectclass:"pii" json:"email"`
+	Note  string `json:"note"`
+}
+
+type ClassifiedCustomer struct {
+	ID       string              `protectfor:"update" json:"id"`
+	Name     string              `protectclass:"pii,searchable" json:"name"`
+	Contacts []ClassifiedContact `json:"contacts"`
+	Billing  *ClassifiedContact  `protectclass:"pii" json:"billing"`
+}
+
+func TestClassifiedFields(t *testing.T) {
+	assert.Equal(t, []string{
+		"Name",
+		"Contacts[].Email",
+		"Billing",
+		"Billing.Email",
+	}, ClassifiedFields("pii", &ClassifiedCustomer{}))
+	assert.Equal(t, []string{"Name"}, ClassifiedFields("searchable", ClassifiedCustomer{}))
+	assert.Nil(t, ClassifiedFields("", &ClassifiedCustomer{}))
+	assert.Nil(t, ClassifiedFields("pii", nil))
+}
+
+func TestClassUsedAsProtectionTag(t *testing.T) {
+	src := &ClassifiedCustomer{
+		ID:       "new",
+		Name:     "new",
+		Contacts: []ClassifiedContact{{Email: "new@example.com", Note: "new"}},
+	}
+	dst := &ClassifiedCustomer{
+		ID:       "old",
+		Name:     "old",
+		Contacts: []ClassifiedContact{{Email: "old@example.com", Note: "old"}},
+	}
+
+	err := Copy("pii", src, dst, WithSliceOption("Contacts", "match"))
+	assert.NoError(t, err)
+	assert.Equal(t, &ClassifiedCustomer{
+		ID:       "new",
+		Name:     "old",
+		Contacts: []ClassifiedContact{{Email: "old@example.com", Note: "new"}},
+	}, dst)
+
+	// Classes are combined with protectfor tags
+	assert.Equal(t, []string{"ID"}, ProtectedFields("update", &ClassifiedCustomer{}))
+	assert.Equal(t, []string{"Name", "Contacts[].Email", "Billing"}, ProtectedFields("pii", &ClassifiedCustomer{}))
+
+	d := Describe(&ClassifiedCustomer{})
+	assert.Equal(t, []string{"pii", "searchable"}, d.Fields[1].Classes)
+	assert.False(t, d.IsWritable("pii", "Contacts[].Email"))
+	assert.True(t, d.IsWritable("update", "Contacts[].Email"))
+}
@@ -0,0 +1,97 @@
+package protect
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Codec encodes and decodes values in a serialization format,
+// so that the same protected encode and decode can be reused by message-queue consumers,
+// RPC layers and snapshots between internal services.
+// Implement it to use other formats such as YAML, MessagePack or CBOR.
+type Codec interface {
+	Encode(w io.Writer, v interface{}) error
+	Decode(r io.Reader, v interface{}) error
+}
+
+// JSONCodec is the Codec with encoding/json.
+var JSONCodec Codec = jsonCodec{}
+
+// jsonCodec is the Codec with encoding/json.
+type jsonCodec struct{}
+
+// Encode encodes v with json.
+func (jsonCodec) Encode(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+// Decode decodes v with json.
+func (jsonCodec) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+// GobCodec is the Codec with encoding/gob.
+var GobCodec Codec = gobCodec{}
+
+// gobCodec is the Codec with encoding/gob.
+type gobCodec struct{}
+
+// Encode encodes v with gob.
+func (gobCodec) Encode(w io.Writer, v interface{}) error {
+	return gob.NewEncoder(w).Encode(v)
+}
+
+// Decode decodes v with gob.
+func (gobCodec) Decode(r io.Reader, v interface{}) error {
+	return gob.NewDecoder(r).Decode(v)
+}
+
+// Encode encodes v with the codec, stripping fields protected for the tag.
+// See Protector.Encode for details.
+func Encode(codec Codec, tag string, w io.Writer, v interface{}) error {
+	return DefaultProtector.Encode(codec, tag, w, v)
+}
+
+// Encode encodes v with the codec, stripping fields protected for the tag.
+// Protected fields are left zero at any depth as CloneWritable does,
+// so sensitive fields never leave the service.
+func (p *Protector) Encode(codec Codec, tag string, w io.Writer, v interface{}) error {
+	clone, err := p.cloneWritable(tag, v)
+	if err != nil {
+		return err
+	}
+	return codec.Encode(w, clone)
+}
+
+// Decode decodes a value with the codec and copies it to dst with protection by the tag.
+// See Protector.Decode for details.
+func Decode(codec Codec, tag string, r io.Reader, dst interface{}) error {
+	return DefaultProtector.Decode(codec, tag, r, dst)
+}
+
+// Decode decodes a value with the codec and copies it to dst with protection by the tag as Copy does,
+// so server-managed fields in dst are kept whatever the encoded value has, like Decoder does for JSON.
+// The value is decoded onto a clone of dst as protectecho.Bind does,
+// so fields absent in the encoded value keep their values.
+// dst must be a pointer; dst is not modified if decoding fails.
+func (p *Protector) Decode(codec Codec, tag string, r io.Reader, dst interface{}) error {
+	dstVal := reflect.ValueOf(dst)
+	if dstVal.Kind() != reflect.Ptr {
+		return fmt.Errorf("%w, got %T: pass the address of the destination (e.g. &dst)", ErrDstNotPointer, dst)
+	}
+	if dstVal.IsNil() {
+		return fmt.Errorf("dst must not be nil pointer")
+	}
+
+	decoded, err := p.cloneWithState(p.newCopyState("", nil), dst)
+	if err != nil {
+		return err
+	}
+	if err := codec.Decode(r, decoded); err != nil {
+		return err
+	}
+	return p.Copy(tag, decoded, dst)
+}
@@ -0,0 +1,68 @@
+package protect
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGobCodec(t *testing.T) {
+	t.Run("encode strips protected fields", func(t *testing.T) {
+		src := &NestedStruct{
+			ID:     "1",
+			Parent: &SimpleStruct{ID: "p1", Code: "pc1", Name: "Parent"},
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, Encode(GobCodec, "update", &buf, src))
+
+		var decoded NestedStruct
+		require.NoError(t, GobCodec.Decode(&buf, &decoded))
+		assert.Equal(t, NestedStruct{Parent: &SimpleStruct{Name: "Parent"}}, decoded)
+		// The source is untouched
+		assert.Equal(t, "p1", src.Parent.ID)
+	})
+
+	t.Run("decode protects fields", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, GobCodec.Encode(&buf, SimpleStruct{ID: "forged", Code: "forged", Name: "New"}))
+
+		dst := SimpleStruct{ID: "1", Code: "A", Name: "Old"}
+		require.NoError(t, Decode(GobCodec, "update", &buf, &dst))
+		assert.Equal(t, SimpleStruct{ID: "1", Code: "A", Name: "New"}, dst)
+	})
+
+	t.Run("decode errors", func(t *testing.T) {
+		dst := SimpleStruct{Name: "Old"}
+		err := Decode(GobCodec, "update", bytes.NewReader([]byte("broken")), &dst)
+		assert.Error(t, err)
+		assert.Equal(t, "Old", dst.Name)
+
+		err = Decode(GobCodec, "update", bytes.NewReader(nil), dst)
+		assert.ErrorIs(t, err, ErrDstNotPointer)
+	})
+}
+
+func TestJSONCodec(t *testing.T) {
+	t.Run("encode strips protected fields", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Encode(JSONCodec, "update", &buf, &SimpleStruct{ID: "1", Code: "A", Name: "Name"}))
+		assert.JSONEq(t, `{"ID":"","Code":"","Name":"Name"}`, buf.String())
+	})
+
+	t.Run("decode protects fields", func(t *testing.T) {
+		dst := SimpleStruct{ID: "1", Code: "A", Name: "Old"}
+		err := Decode(JSONCodec, "update", bytes.NewReader([]byte(`{"ID":"forged","Name":"New"}`)), &dst)
+		require.NoError(t, err)
+		assert.Equal(t, SimpleStruct{ID: "1", Code: "A", Name: "New"}, dst)
+	})
+
+	t.Run("absent fields keep their values", func(t *testing.T) {
+		dst := NestedStruct{ID: "1", Child: SimpleStruct{Name: "Child"}}
+		err := Decode(JSONCodec, "create", bytes.NewReader([]byte(`{"Parent":{"Name":"Parent"}}`)), &dst)
+		require.NoError(t, err)
+		assert.Equal(t, NestedStruct{ID: "1", Child: SimpleStruct{Name: "Child"}, Parent: &SimpleStruct{Name: "Parent"}}, dst)
+	})
+}
@@ -0,0 +1,84 @@
+package protect
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestConcurrentCopySlice runs CopySlice with different options concurrently on DefaultProtector.
+// Run with -race to detect shared state between calls.
+func TestConcurrentCopySlice(t *testing.T) {
+	options := []string{"overwrite", "match", "longer", "shorter"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		option := options[i%len(options)]
+		wg.Add(1)
+		go func(i int, option string) {
+			defer wg.Done()
+
+			src := []SimpleStruct{
+				{ID: "1", Code: "A", Name: fmt.Sprintf("First%d", i)},
+				{ID: "2", Code: "B", Name: fmt.Sprintf("Second%d", i)},
+			}
+			// Empty destinations share the same address, which used to make options leak between calls
+			var dst []SimpleStruct
+			if i%2 == 0 {
+				dst = []SimpleStruct{{ID: "X", Code: "Y", Name: "Existing"}}
+			}
+			dstLen := len(dst)
+
+			err := CopySlice("create", &src, &dst, option)
+			assert.NoError(t, err)
+
+			switch option {
+			case "overwrite":
+				assert.Equal(t, 2, len(dst))
+				assert.Equal(t, "1", dst[0].ID)
+			case "match":
+				assert.Equal(t, 2, len(dst))
+				assert.Empty(t, dst[1].ID)
+			case "longer":
+				assert.Equal(t, 2, len(dst))
+			case "shorter":
+				assert.Equal(t, dstLen, len(dst))
+			}
+			for j := range dst {
+				if j < dstLen && option != "overwrite" {
+					assert.Equal(t, "X", dst[j].ID)
+				}
+			}
+		}(i, option)
+	}
+	wg.Wait()
+}
+
+// TestConcurrentOptions runs Copy with different per-call options concurrently on a shared Protector.
+func TestConcurrentOptions(t *testing.T) {
+	p := createTestProtector()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			src := VendorHolder{Vendor: VendorStruct{ID: "1", Name: "Vendor"}}
+			dst := VendorHolder{}
+
+			if i%2 == 0 {
+				err := p.Copy("update", &src, &dst, WithPrimitiveStruct(VendorStruct{}))
+				assert.NoError(t, err)
+				assert.Equal(t, "1", dst.Vendor.ID)
+			} else {
+				err := p.Copy("update", &src, &dst)
+				assert.NoError(t, err)
+				assert.Empty(t, dst.Vendor.ID)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
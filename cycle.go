@@ -0,0 +1,70 @@
+package protect
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// CyclePolicy specifies how Copy and Clone handle pointers pointing back to their ancestors.
+type CyclePolicy int32
+
+const (
+	// CycleError fails copies with ErrCycle. This is the default.
+	CycleError CyclePolicy = iota
+	// CycleReuse points to the already copied ancestor, so that the result has the same cycles as the source.
+	CycleReuse
+)
+
+// ErrCycle is returned when a pointer points back to its ancestor with CycleError.
+var ErrCycle = errors.New("cycle detected")
+
+// SetCyclePolicy sets how Copy and Clone handle self-referential structures,
+// e.g. a struct whose pointer field eventually points back to itself.
+// Cycles are detected only through pointers; pointers shared without forming cycles are copied each time.
+// Clones always reuse cycles regardless of the policy, as they never fail on valid values.
+func (p *Protector) SetCyclePolicy(policy CyclePolicy) {
+	p.cyclePolicy.Store(int32(policy))
+}
+
+// pointerKey identifies a pointer being copied.
+// The type is included as a struct and its first field share the address.
+type pointerKey struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// enterPointer registers the src pointer as an ancestor copied to the dst pointer,
+// and returns a function to unregister it.
+func (s *copyState) enterPointer(src, dst reflect.Value) func() {
+	if s.ancestors == nil {
+		s.ancestors = make(map[pointerKey]reflect.Value)
+	}
+	key := pointerKey{addr: src.Pointer(), typ: src.Type()}
+	s.ancestors[key] = dst
+	return func() {
+		delete(s.ancestors, key)
+	}
+}
+
+// ancestor returns the copy of the src pointer if src points back to its ancestor.
+// It returns an error if the cycle cannot be reused.
+func (s *copyState) ancestor(src, dst reflect.Value) (reflect.Value, bool, error) {
+	copied, ok := s.ancestors[pointerKey{addr: src.Pointer(), typ: src.Type()}]
+	if !ok {
+		return reflect.Value{}, false, nil
+	}
+	reuse := s.cloning || CyclePolicy(s.protector.cyclePolicy.Load()) == CycleReuse
+	if !reuse || copied.Type() != dst.Type() {
+		return reflect.Value{}, true, fmt.Errorf("%w at %s: %s points back to its ancestor", ErrCycle, displayPath(s.path), src.Type())
+	}
+	return copied, true, nil
+}
+
+// displayPath returns the path for messages.
+func displayPath(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
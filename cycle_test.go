@@ -0,0 +1,88 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type GraphNode struct {
+	ID   string `protectfor:"update"`
+	Name string
+	Next *GraphNode
+}
+
+// newRing returns nodes a -> b -> a.
+func newRing() *GraphNode {
+	a := &GraphNode{ID: "a", Name: "A"}
+	a.Next = &GraphNode{ID: "b", Name: "B", Next: a}
+	return a
+}
+
+func TestCycles(t *testing.T) {
+	t.Run("errors by default", func(t *testing.T) {
+		var dst GraphNode
+		err := Copy("update", newRing(), &dst)
+		assert.ErrorIs(t, err, ErrCycle)
+		assert.ErrorContains(t, err, "Next.Next")
+
+	})
+
+	t.Run("clones reuse cycles by default", func(t *testing.T) {
+		src := newRing()
+		clone := Clone(src).(*GraphNode)
+		assert.Same(t, clone, clone.Next.Next)
+
+		_, _, err := CloneWithStats(src)
+		assert.NoError(t, err)
+
+		typed := CloneT(src)
+		assert.NotSame(t, src, typed)
+		assert.Same(t, typed, typed.Next.Next)
+
+		var count int
+		for v, err := range CloneSeq(func(yield func(*GraphNode) bool) { yield(src) }) {
+			assert.NoError(t, err)
+			assert.Same(t, v, v.Next.Next)
+			count++
+		}
+		assert.Equal(t, 1, count)
+
+		r, err := Reconcile("update", map[string]*GraphNode{"a": src}, map[string]*GraphNode{"b": newRing()})
+		require.NoError(t, err)
+		assert.Same(t, r.Create["a"], r.Create["a"].Next.Next)
+	})
+
+	t.Run("cycles reused", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		p.SetCyclePolicy(CycleReuse)
+
+		src := newRing()
+		var dst GraphNode
+		err := p.Copy("update", src, &dst)
+		require.NoError(t, err)
+		assert.Equal(t, "", dst.ID)
+		assert.Equal(t, "A", dst.Name)
+		assert.Equal(t, "B", dst.Next.Name)
+		assert.Same(t, &dst, dst.Next.Next)
+
+		clone := p.Clone(src).(*GraphNode)
+		assert.Equal(t, "a", clone.ID)
+		assert.NotSame(t, src, clone)
+		assert.NotSame(t, src.Next, clone.Next)
+		assert.Same(t, clone, clone.Next.Next)
+
+		// Elements cloned in overwrite mode
+		nodes := []*GraphNode{src}
+		clones := p.Clone(nodes).([]*GraphNode)
+		assert.Same(t, clones[0], clones[0].Next.Next)
+	})
+
+	t.Run("shared pointers are not cycles", func(t *testing.T) {
+		shared := &GraphNode{ID: "s"}
+		src := []*GraphNode{shared, shared}
+		clone := Clone(src).([]*GraphNode)
+		assert.Equal(t, "s", clone[1].ID)
+	})
+}
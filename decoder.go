@@ -0,0 +1,385 @@
+package protect
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Decoder reads and decodes JSON values from an input stream
+// skipping object keys for fields protected for the tag.
+// Values of protected keys are consumed at the token level and never decoded into the destination,
+// so protected data doesn't even materialize in Go values.
+// Fields protected in the destination keep their existing values.
+type Decoder struct {
+	p   *Protector
+	tag string
+	dec *json.Decoder
+
+	disallowProtectedFields bool
+	disallowUnknownFields   bool
+}
+
+// ErrProtectedField is wrapped by FieldError when the input contains a protected field
+// and DisallowProtectedFields is set.
+var ErrProtectedField = errors.New("protected field")
+
+// ErrUnknownField is wrapped by FieldError when the input contains a field not in the destination
+// and DisallowUnknownFields is set.
+var ErrUnknownField = errors.New("unknown field")
+
+// FieldError is returned by Decoder when a field in the input is rejected.
+type FieldError struct {
+	// Pointer is the RFC 6901 JSON pointer of the rejected field in the input.
+	Pointer string
+	// Path is the Go field path of the rejected field in the format of ProtectedFields,
+	// e.g. "Children[].ID".
+	// For unknown fields, which have no Go fields, it is the path of the struct containing the key.
+	Path string
+	// Err is the reason of the rejection, ErrProtectedField or ErrUnknownField.
+	Err error
+}
+
+// Error returns the description of the error.
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v: %s", e.Err, e.Pointer)
+}
+
+// Unwrap returns the reason of the rejection.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// NewDecoder returns a new Decoder reading from r and protecting fields for the tag.
+func NewDecoder(tag string, r io.Reader) *Decoder {
+	return DefaultProtector.NewDecoder(tag, r)
+}
+
+// NewDecoder returns a new Decoder reading from r and protecting fields for the tag.
+func (p *Protector) NewDecoder(tag string, r io.Reader) *Decoder {
+	dec := json.NewDecoder(r)
+	// Keep numbers as they are written
+	dec.UseNumber()
+	return &Decoder{
+		p:   p,
+		tag: tag,
+		dec: dec,
+	}
+}
+
+// DisallowProtectedFields causes Decode to return a FieldError wrapping ErrProtectedField
+// when the input contains a key for a field protected for the tag,
+// instead of skipping it.
+func (d *Decoder) DisallowProtectedFields() {
+	d.disallowProtectedFields = true
+}
+
+// DisallowUnknownFields causes Decode to return a FieldError wrapping ErrUnknownField
+// when the input contains a key which doesn't match any field of a destination struct.
+func (d *Decoder) DisallowUnknownFields() {
+	d.disallowUnknownFields = true
+}
+
+// Decode reads the next JSON value from its input and stores it in the value pointed to by v
+// as json.Unmarshal does, skipping keys of fields protected for the tag.
+func (d *Decoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("%w, got %T: pass the address of the destination (e.g. &dst)", ErrDstNotPointer, v)
+	}
+
+	var buf bytes.Buffer
+	if err := d.filterValue(rv.Type().Elem(), "", "", &buf); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(buf.Bytes(), v)
+}
+
+// More reports whether there is another element in the current array or object being parsed.
+func (d *Decoder) More() bool {
+	return d.dec.More()
+}
+
+// filterValue reads the next JSON value and writes it to w, dropping protected keys for t.
+// t may be nil for values which have no corresponding Go type.
+// pointer is the JSON pointer of the value, and path is the Go field path of the value.
+func (d *Decoder) filterValue(t reflect.Type, pointer, path string, w *bytes.Buffer) error {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return err
+	}
+
+	// Values decoded by themselves are passed through as they are
+	t = d.filterType(t)
+
+	switch tok {
+	case json.Delim('{'):
+		return d.filterObject(t, pointer, path, w)
+	case json.Delim('['):
+		return d.filterArray(t, pointer, path, w)
+	default:
+		b, err := json.Marshal(tok)
+		if err != nil {
+			return err
+		}
+		w.Write(b)
+		return nil
+	}
+}
+
+// filterType returns the type to filter the value with.
+// It returns nil if the value should be passed through without filtering.
+func (d *Decoder) filterType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		if implementsUnmarshaler(t) {
+			return nil
+		}
+		t = t.Elem()
+	}
+	if t == nil || implementsUnmarshaler(t) || implementsUnmarshaler(reflect.PointerTo(t)) {
+		return nil
+	}
+	if t.Kind() == reflect.Struct && d.p.IsPrimitiveStruct(t) {
+		return nil
+	}
+	return t
+}
+
+// implementsUnmarshaler checks if t decodes JSON values by itself.
+func implementsUnmarshaler(t reflect.Type) bool {
+	return t.Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) ||
+		t.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// filterObject writes a JSON object whose '{' is already read, dropping protected keys for t.
+func (d *Decoder) filterObject(t reflect.Type, pointer, path string, w *bytes.Buffer) error {
+	var fields map[string]jsonField
+	if t != nil && t.Kind() == reflect.Struct {
+		fields = d.p.jsonFields(t)
+	}
+
+	w.WriteByte('{')
+	first := true
+	for d.dec.More() {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("unexpected token %v in object", tok)
+		}
+
+		keyPointer := pointer + "/" + escapeJSONPointer(key)
+
+		var valueType reflect.Type
+		valuePath := path
+		switch {
+		case fields != nil:
+			field, found := lookupJSONField(fields, key)
+			if !found && d.disallowUnknownFields {
+				return &FieldError{Pointer: keyPointer, Path: path, Err: ErrUnknownField}
+			}
+			if found {
+				valuePath = joinPath(path, field.name)
+			}
+			if found && field.isProtected(d.tag) {
+				if d.disallowProtectedFields {
+					return &FieldError{Pointer: keyPointer, Path: valuePath, Err: ErrProtectedField}
+				}
+				if err := d.skipValue(); err != nil {
+					return err
+				}
+				continue
+			}
+			if found {
+				valueType = field.typ
+			}
+		case t != nil && t.Kind() == reflect.Map:
+			valueType = t.Elem()
+			valuePath = joinPath(path, "[]")
+		}
+
+		if !first {
+			w.WriteByte(',')
+		}
+		first = false
+
+		b, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		w.Write(b)
+		w.WriteByte(':')
+
+		if err := d.filterValue(valueType, keyPointer, valuePath, w); err != nil {
+			return err
+		}
+	}
+
+	// Consume '}'
+	if _, err := d.dec.Token(); err != nil {
+		return err
+	}
+	w.WriteByte('}')
+	return nil
+}
+
+// filterArray writes a JSON array whose '[' is already read, filtering elements with the element type of t.
+func (d *Decoder) filterArray(t reflect.Type, pointer, path string, w *bytes.Buffer) error {
+	var elemType reflect.Type
+	if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		elemType = t.Elem()
+	}
+
+	w.WriteByte('[')
+	for i := 0; d.dec.More(); i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+
+		if err := d.filterValue(elemType, pointer+"/"+strconv.Itoa(i), joinPath(path, "[]"), w); err != nil {
+			return err
+		}
+	}
+
+	// Consume ']'
+	if _, err := d.dec.Token(); err != nil {
+		return err
+	}
+	w.WriteByte(']')
+	return nil
+}
+
+// escapeJSONPointer escapes a reference token of a JSON pointer as defined in RFC 6901.
+func escapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// skipValue reads the next JSON value and discards it.
+func (d *Decoder) skipValue() error {
+	depth := 0
+	for {
+		tok, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// jsonField is a struct field decoded from a JSON object key.
+type jsonField struct {
+	// name is the Go name of the field.
+	name string
+	// typ is the type of the field.
+	typ reflect.Type
+	// tagValues holds the protection tag values of the field and the embedded fields promoting it.
+	tagValues []string
+}
+
+// isProtected checks if the field or any of the embedded fields promoting it is protected for the tag,
+// as Copy skips embedded fields protected for the tag as a whole.
+func (f jsonField) isProtected(tag string) bool {
+	for _, tagValue := range f.tagValues {
+		if isProtected(tagValue, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonFields returns fields of the struct type t by JSON names, following the rules of encoding/json.
+// Fields of embedded structs without JSON names are promoted.
+func (p *Protector) jsonFields(t reflect.Type) map[string]jsonField {
+	fields := make(map[string]jsonField)
+	p.collectJSONFields(t, nil, nil, fields, map[reflect.Type]bool{})
+	return fields
+}
+
+// collectJSONFields collects fields of t into fields.
+// inherited holds the protection tag values of the embedded fields promoting fields of t,
+// and promoted holds tags for promoted fields attached by the embedding struct,
+// which apply only to the fields of t as Copy does.
+func (p *Protector) collectJSONFields(t reflect.Type, inherited []string, promoted map[string]string, fields map[string]jsonField, visiting map[reflect.Type]bool) {
+	if visiting[t] {
+		return
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var embedded []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(jsonTag, ",")
+
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, field)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		tagValues := append(inherited[:len(inherited):len(inherited)], p.promotedProtectionTagValue(field, promoted))
+		fields[name] = jsonField{name: field.Name, typ: field.Type, tagValues: tagValues}
+	}
+
+	// Fields of the outer struct take precedence over promoted fields
+	for _, field := range embedded {
+		ft := field.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		tagValues := append(inherited[:len(inherited):len(inherited)], p.promotedProtectionTagValue(field, promoted))
+		promotedFields := make(map[string]jsonField)
+		p.collectJSONFields(ft, tagValues, promotedTagValues(field.Tag.Get(p.tagName)), promotedFields, visiting)
+		for name, f := range promotedFields {
+			if _, ok := fields[name]; !ok {
+				fields[name] = f
+			}
+		}
+	}
+}
+
+// lookupJSONField looks up the field for the JSON key as encoding/json does:
+// an exact match is preferred, then a case-insensitive match.
+func lookupJSONField(fields map[string]jsonField, key string) (jsonField, bool) {
+	if field, ok := fields[key]; ok {
+		return field, true
+	}
+	for name, field := range fields {
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+	return jsonField{}, false
+}
@@ -0,0 +1,198 @@
+package protect
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type DecodeChild struct {
+	ID   string `protectfor:"update" json:"id"`
+	Name string `json:"name"`
+}
+
+type DecodeEmbedded struct {
+	Owner string `protectfor:"update" json:"owner"`
+}
+
+type DecodeStruct struct {
+	DecodeEmbedded
+	ID        string                 `protectfor:"create,update" json:"id"`
+	Secret    string                 `protectfor:"create,update" json:"secret"`
+	Name      string                 `json:"name"`
+	CreatedAt time.Time              `protectfor:"update" json:"createdAt"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+	Child     *DecodeChild           `json:"child"`
+	Children  []DecodeChild          `json:"children"`
+	ByKey     map[string]DecodeChild `json:"byKey"`
+	Extra     interface{}            `json:"extra"`
+}
+
+func TestDecoder(t *testing.T) {
+	input := `{
+		"id": "123",
+		"secret": {"nested": ["value", 1]},
+		"Name": "Test",
+		"owner": "attacker",
+		"createdAt": "2020-01-01T00:00:00Z",
+		"updatedAt": "2021-01-01T00:00:00Z",
+		"child": {"id": "c1", "name": "Child"},
+		"children": [{"id": "c2", "name": "Child2"}],
+		"byKey": {"k": {"id": "c3", "name": "Child3"}},
+		"extra": {"id": "not protected", "n": 1.50},
+		"unknown": [1, 2, 3]
+	}`
+
+	t.Run("update tag", func(t *testing.T) {
+		dst := DecodeStruct{ID: "X", Secret: "S", DecodeEmbedded: DecodeEmbedded{Owner: "owner"}}
+
+		err := NewDecoder("update", strings.NewReader(input)).Decode(&dst)
+		assert.NoError(t, err)
+
+		// Protected fields keep the existing values
+		assert.Equal(t, "X", dst.ID)
+		assert.Equal(t, "S", dst.Secret)
+		assert.Equal(t, "owner", dst.Owner)
+		assert.True(t, dst.CreatedAt.IsZero())
+		assert.Empty(t, dst.Child.ID)
+		assert.Empty(t, dst.Children[0].ID)
+		assert.Empty(t, dst.ByKey["k"].ID)
+
+		// Other fields are decoded
+		assert.Equal(t, "Test", dst.Name)
+		assert.Equal(t, 2021, dst.UpdatedAt.Year())
+		assert.Equal(t, "Child", dst.Child.Name)
+		assert.Equal(t, "Child2", dst.Children[0].Name)
+		assert.Equal(t, "Child3", dst.ByKey["k"].Name)
+		assert.Equal(t, map[string]interface{}{"id": "not protected", "n": 1.5}, dst.Extra)
+	})
+
+	t.Run("create tag", func(t *testing.T) {
+		dst := DecodeStruct{}
+
+		err := NewDecoder("create", strings.NewReader(input)).Decode(&dst)
+		assert.NoError(t, err)
+
+		assert.Empty(t, dst.ID)
+		assert.Empty(t, dst.Secret)
+		assert.Equal(t, "attacker", dst.Owner)
+		assert.Equal(t, 2020, dst.CreatedAt.Year())
+		assert.Equal(t, "c1", dst.Child.ID)
+	})
+
+	t.Run("slice of structs", func(t *testing.T) {
+		dst := []DecodeChild{}
+
+		err := NewDecoder("update", strings.NewReader(`[{"id":"1","name":"A"},{"ID":"2","name":"B"}]`)).Decode(&dst)
+		assert.NoError(t, err)
+		assert.Equal(t, []DecodeChild{{Name: "A"}, {Name: "B"}}, dst)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		dec := NewDecoder("update", strings.NewReader(`{"id":"1","name":"A"} {"id":"2","name":"B"}`))
+
+		var first, second DecodeChild
+		assert.NoError(t, dec.Decode(&first))
+		assert.True(t, dec.More())
+		assert.NoError(t, dec.Decode(&second))
+		assert.Equal(t, DecodeChild{Name: "A"}, first)
+		assert.Equal(t, DecodeChild{Name: "B"}, second)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		dst := DecodeChild{}
+		err := NewDecoder("update", strings.NewReader(`{"id":`)).Decode(&dst)
+		assert.Error(t, err)
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		dst := DecodeChild{}
+		err := NewDecoder("update", strings.NewReader(`{"name":1}`)).Decode(&dst)
+		assert.Error(t, err)
+	})
+
+	t.Run("dst passed by value", func(t *testing.T) {
+		dst := DecodeChild{}
+		err := NewDecoder("update", strings.NewReader(`{}`)).Decode(dst)
+		assert.ErrorIs(t, err, ErrDstNotPointer)
+	})
+}
+
+func TestDecoderDisallow(t *testing.T) {
+	t.Run("protected field", func(t *testing.T) {
+		dec := NewDecoder("update", strings.NewReader(`{"name":"A","children":[{"name":"B"},{"id":"2"}]}`))
+		dec.DisallowProtectedFields()
+
+		dst := DecodeStruct{}
+		err := dec.Decode(&dst)
+		assert.ErrorIs(t, err, ErrProtectedField)
+
+		var fieldErr *FieldError
+		assert.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "/children/1/id", fieldErr.Pointer)
+		assert.Equal(t, "Children[].ID", fieldErr.Path)
+
+		// Nothing is decoded
+		assert.Empty(t, dst.Name)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		dec := NewDecoder("update", strings.NewReader(`{"byKey":{"a/b":{"nmae":"typo"}}}`))
+		dec.DisallowUnknownFields()
+
+		dst := DecodeStruct{}
+		err := dec.Decode(&dst)
+		assert.ErrorIs(t, err, ErrUnknownField)
+
+		var fieldErr *FieldError
+		assert.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "/byKey/a~1b/nmae", fieldErr.Pointer)
+		assert.Equal(t, "ByKey[]", fieldErr.Path)
+		assert.Equal(t, "unknown field: /byKey/a~1b/nmae", err.Error())
+	})
+
+	t.Run("unknown fields in interface values are allowed", func(t *testing.T) {
+		dec := NewDecoder("update", strings.NewReader(`{"extra":{"anything":1}}`))
+		dec.DisallowUnknownFields()
+		dec.DisallowProtectedFields()
+
+		dst := DecodeStruct{}
+		assert.NoError(t, dec.Decode(&dst))
+	})
+}
+
+type DecodeBase struct {
+	ID string `json:"id"`
+}
+
+type DecodeMiddle struct {
+	DecodeBase
+	Note string `json:"note"`
+}
+
+type DecodeOuter struct {
+	DecodeMiddle `protectfor:"ID=update,Note=update"`
+	Name         string `json:"name"`
+}
+
+func TestDecoderNestedEmbedding(t *testing.T) {
+	// Tags for promoted fields apply only to the fields of the embedded struct, as Copy does
+	input := `{"id": "new", "note": "new", "name": "new"}`
+
+	dst := DecodeOuter{DecodeMiddle: DecodeMiddle{DecodeBase: DecodeBase{ID: "old"}, Note: "old"}}
+	err := NewDecoder("update", strings.NewReader(input)).Decode(&dst)
+	assert.NoError(t, err)
+	assert.Equal(t, DecodeOuter{DecodeMiddle: DecodeMiddle{DecodeBase: DecodeBase{ID: "new"}, Note: "old"}, Name: "new"}, dst)
+
+	copied := DecodeOuter{DecodeMiddle: DecodeMiddle{DecodeBase: DecodeBase{ID: "old"}, Note: "old"}}
+	src := DecodeOuter{DecodeMiddle: DecodeMiddle{DecodeBase: DecodeBase{ID: "new"}, Note: "new"}, Name: "new"}
+	assert.NoError(t, Copy("update", &src, &copied))
+	assert.Equal(t, copied, dst)
+
+	dec := NewDecoder("update", strings.NewReader(`{"id": "new"} {"note": "new"}`))
+	dec.DisallowProtectedFields()
+	assert.NoError(t, dec.Decode(&DecodeOuter{}))
+	assert.ErrorIs(t, dec.Decode(&DecodeOuter{}), ErrProtectedField)
+}
@@ -0,0 +1,178 @@
+package protect
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Descriptor describes protection rules of a type in a language-neutral form.
+// It is designed to be serialized with encoding/json,
+// so that gateways, CLIs and services in other languages can evaluate
+// whether a field is writable for a tag without linking the Go types.
+type Descriptor struct {
+	// Type is the name of the described Go type.
+	Type string `json:"type"`
+	// Allowlist is true in the allowlist mode,
+	// where fields are writable only for the tags listed in their CopyFor.
+	Allowlist bool `json:"allowlist,omitempty"`
+	// Fields are the fields of the type in the order of declaration, depth first.
+	Fields []FieldDescriptor `json:"fields"`
+}
+
+// FieldDescriptor describes protection rules of a field.
+type FieldDescriptor struct {
+	// Path is the Go field path in the format of ProtectedFields, e.g. "Items[].ID".
+	Path string `json:"path"`
+	// JSONPath is the path of the field with JSON names in the same format, e.g. "items[].id".
+	// It is empty if the field is not encoded in JSON.
+	JSONPath string `json:"jsonPath,omitempty"`
+	// ProtectFor lists the tags the field is protected for.
+	// It may contain the wildcard "*" and negated tags like "!create".
+	// Tags combined from embedding structs with SetCombineEmbeddedTags are joined with ";"
+	// as a separate group evaluated independently, e.g. "!create;!import".
+	ProtectFor []string `json:"protectFor,omitempty"`
+	// CopyFor lists the tags the field is copied for in the allowlist mode.
+	// The field is protected for other tags regardless of ProtectFor.
+	CopyFor []string `json:"copyFor,omitempty"`
+	// Options lists the protection options of the field, e.g. "shallow".
+	Options []string `json:"options,omitempty"`
+	// Classes lists the classes of the field, e.g. "pii".
+	// The field is also protected for its classes.
+	Classes []string `json:"classes,omitempty"`
+}
+
+// Describe returns the Descriptor of the type of v.
+// See Protector.Describe for details.
+func Describe(v interface{}) *Descriptor {
+	return DefaultProtector.Describe(v)
+}
+
+// Describe returns the Descriptor of the type of v.
+// Fields in primitive structs are not described,
+// and fields of recursive types are described only at their first occurrence.
+func (p *Protector) Describe(v interface{}) *Descriptor {
+	if v == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(v)
+	allowlistTag, _ := p.allowlistTag.Load().(string)
+	d := &Descriptor{Type: t.String(), Allowlist: allowlistTag != ""}
+	p.collectFieldDescriptors(t, "", "", true, allowlistTag, nil, map[reflect.Type]bool{}, &d.Fields)
+	return d
+}
+
+// collectFieldDescriptors collects descriptors of fields in t into fields.
+// encoded reports whether the value is encoded in JSON,
+// allowlistTag is the name of the allowlist tag or "" outside the allowlist mode,
+// and promoted holds tags for promoted fields attached by the embedding struct.
+func (p *Protector) collectFieldDescriptors(t reflect.Type, path, jsonPath string, encoded bool, allowlistTag string, promoted map[string]string, visiting map[reflect.Type]bool, fields *[]FieldDescriptor) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		p.collectFieldDescriptors(t.Elem(), path, jsonPath, encoded, allowlistTag, promoted, visiting, fields)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if encoded {
+			jsonPath = joinPath(jsonPath, "[]")
+		}
+		p.collectFieldDescriptors(t.Elem(), joinPath(path, "[]"), jsonPath, encoded, allowlistTag, nil, visiting, fields)
+	case reflect.Struct:
+		if p.IsPrimitiveStruct(t) || visiting[t] {
+			return
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldJSONPath := ""
+			fieldEncoded := encoded
+			jsonTag := field.Tag.Get("json")
+			name, _, _ := strings.Cut(jsonTag, ",")
+			switch {
+			case !encoded || jsonTag == "-":
+				fieldEncoded = false
+			case field.Anonymous && name == "":
+				// Fields of embedded structs are promoted
+				fieldJSONPath = jsonPath
+			case name == "":
+				fieldJSONPath = joinPath(jsonPath, field.Name)
+			default:
+				fieldJSONPath = joinPath(jsonPath, name)
+			}
+
+			fieldPath := joinPath(path, field.Name)
+			var copyFor []string
+			if allowlistTag != "" {
+				copyFor = splitTagValue(field.Tag.Get(allowlistTag))
+			}
+			*fields = append(*fields, FieldDescriptor{
+				Path:       fieldPath,
+				JSONPath:   fieldJSONPath,
+				ProtectFor: splitTagValue(p.promotedProtectForTagValue(field, promoted)),
+				CopyFor:    copyFor,
+				Options:    splitTagValue(field.Tag.Get(p.optTagName)),
+				Classes:    splitTagValue(field.Tag.Get(classTagName)),
+			})
+
+			p.collectFieldDescriptors(field.Type, fieldPath, fieldJSONPath, fieldEncoded, allowlistTag, p.embeddedPromotedTagValues(field), visiting, fields)
+		}
+	}
+}
+
+// splitTagValue splits a comma-separated tag value, dropping empty entries.
+func splitTagValue(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// IsWritable checks if the field at the Go field path is writable for the tag,
+// that is, neither the field nor any of its ancestors is protected or classified for the tag.
+// Wildcard and negated tags (e.g. "*" and "!create") are evaluated as Copy does,
+// and fields are not writable for tags not in CopyFor in the allowlist mode.
+// It returns false for paths not in the descriptor.
+func (d *Descriptor) IsWritable(tag, path string) bool {
+	found := false
+	for _, field := range d.Fields {
+		if field.Path != path && !isAncestorPath(field.Path, path) {
+			continue
+		}
+		if field.Path == path {
+			found = true
+		}
+		if isProtected(strings.Join(field.ProtectFor, ","), tag) || containsString(field.Classes, tag) {
+			return false
+		}
+		if d.Allowlist && tag != "" && !containsString(field.CopyFor, tag) {
+			return false
+		}
+	}
+	return found
+}
+
+// containsString checks if values contains v.
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// isAncestorPath checks if ancestor is a path of an ancestor of the value at path.
+func isAncestorPath(ancestor, path string) bool {
+	if !strings.HasPrefix(path, ancestor) {
+		return false
+	}
+	rest := path[len(ancestor):]
+	return strings.HasPrefix(rest, ".") || strings.HasPrefix(rest, "[]")
+}
@@ -0,0 +1,81 @@
+package protect
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type DescribeItem struct {
+	ID   string `protectfor:"update" json:"id"`
+	Name string `json:"name"`
+}
+
+type DescribeEmbedded struct {
+	Owner string `protectfor:"create, update" json:"owner"`
+}
+
+type DescribeStruct struct {
+	DescribeEmbedded
+	Items    []DescribeItem `json:"items"`
+	Internal *DescribeItem  `protectfor:"create" json:"-"`
+	Raw      *DescribeItem  `protectopt:"shallow"`
+}
+
+func TestDescribe(t *testing.T) {
+	d := Describe(&DescribeStruct{})
+
+	assert.Equal(t, "*protect.DescribeStruct", d.Type)
+	assert.Equal(t, []FieldDescriptor{
+		{Path: "DescribeEmbedded"},
+		{Path: "DescribeEmbedded.Owner", JSONPath: "owner", ProtectFor: []string{"create", "update"}},
+		{Path: "Items", JSONPath: "items"},
+		{Path: "Items[].ID", JSONPath: "items[].id", ProtectFor: []string{"update"}},
+		{Path: "Items[].Name", JSONPath: "items[].name"},
+		{Path: "Internal", ProtectFor: []string{"create"}},
+		{Path: "Internal.ID", ProtectFor: []string{"update"}},
+		{Path: "Internal.Name"},
+		{Path: "Raw", JSONPath: "Raw", Options: []string{"shallow"}},
+		{Path: "Raw.ID", JSONPath: "Raw.id", ProtectFor: []string{"update"}},
+		{Path: "Raw.Name", JSONPath: "Raw.name"},
+	}, d.Fields)
+
+	t.Run("IsWritable", func(t *testing.T) {
+		assert.True(t, d.IsWritable("create", "Items[].ID"))
+		assert.False(t, d.IsWritable("update", "Items[].ID"))
+		assert.False(t, d.IsWritable("update", "DescribeEmbedded.Owner"))
+		// Protected by the ancestor
+		assert.False(t, d.IsWritable("create", "Internal.Name"))
+		assert.True(t, d.IsWritable("update", "Internal.Name"))
+		// Protection of a sibling does not apply
+		assert.True(t, d.IsWritable("update", "Items[].Name"))
+		assert.False(t, d.IsWritable("update", "NoSuchField"))
+	})
+
+	t.Run("JSON round trip", func(t *testing.T) {
+		b, err := json.Marshal(d)
+		assert.NoError(t, err)
+
+		var decoded Descriptor
+		assert.NoError(t, json.Unmarshal(b, &decoded))
+		assert.Equal(t, *d, decoded)
+	})
+
+	t.Run("promoted fields", func(t *testing.T) {
+		d := Describe(PromotedTagStruct{})
+		assert.Equal(t, []FieldDescriptor{
+			{Path: "AuditFields"},
+			{Path: "AuditFields.ID", JSONPath: "ID", ProtectFor: []string{"create", "update"}},
+			{Path: "AuditFields.CreatedAt", JSONPath: "CreatedAt", ProtectFor: []string{"update"}},
+			{Path: "AuditFields.Note", JSONPath: "Note"},
+			{Path: "Name", JSONPath: "Name"},
+		}, d.Fields)
+		assert.False(t, d.IsWritable("update", "AuditFields.ID"))
+		assert.True(t, d.IsWritable("create", "AuditFields.CreatedAt"))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		assert.Nil(t, Describe(nil))
+	})
+}
@@ -0,0 +1,37 @@
+package protect
+
+// CopyT copies src to dst with DefaultProtector like Copy,
+// but src and dst must be of the same type, which is checked at compile time.
+func CopyT[T any](tag string, src, dst *T, opts ...Option) error {
+	return DefaultProtector.Copy(tag, src, dst, opts...)
+}
+
+// CloneT returns a deep copy of src with DefaultProtector like Clone,
+// but returns the value of the concrete type without type assertions.
+// Pointers are deep copied as well, so CloneT(item) returns a new *Item for item of *Item.
+// Cycles are reused as Clone does.
+// It panics if src cannot be cloned.
+func CloneT[T any](src T) T {
+	clone, err := cloneT(DefaultProtector, src)
+	if err != nil {
+		// Clones without options never fail for valid values
+		panic(err)
+	}
+	return clone
+}
+
+// cloneT returns a deep copy of src with p, or the error if src cannot be cloned.
+func cloneT[T any](p *Protector, src T) (T, error) {
+	clone, err := p.cloneWithState(p.newCopyState("", nil), &src)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return *clone.(*T), nil
+}
+
+// ClonePtr returns a pointer to a deep copy of the value src points to, or nil if src is nil.
+// This is the same as CloneT for pointers, spelled to make the intent clear at call sites.
+func ClonePtr[T any](src *T) *T {
+	return CloneT(src)
+}
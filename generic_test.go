@@ -0,0 +1,40 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyT(t *testing.T) {
+	src := SimpleStruct{ID: "1", Code: "A", Name: "New"}
+	dst := SimpleStruct{ID: "2", Code: "B", Name: "Old"}
+
+	err := CopyT("update", &src, &dst)
+	assert.NoError(t, err)
+	assert.Equal(t, SimpleStruct{ID: "2", Code: "B", Name: "New"}, dst)
+
+	t.Run("nil destination", func(t *testing.T) {
+		err := CopyT[SimpleStruct]("update", &src, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestCloneT(t *testing.T) {
+	src := NestedStruct{
+		ID:     "1",
+		Child:  SimpleStruct{ID: "2", Name: "Child"},
+		Parent: &SimpleStruct{ID: "3", Name: "Parent"},
+	}
+
+	clone := CloneT(src)
+	assert.Equal(t, src, clone)
+	assert.NotSame(t, src.Parent, clone.Parent)
+
+	ptr := ClonePtr(&src)
+	assert.Equal(t, src, *ptr)
+	assert.NotSame(t, &src, ptr)
+	assert.NotSame(t, src.Parent, ptr.Parent)
+
+	assert.Nil(t, ClonePtr[NestedStruct](nil))
+}
@@ -0,0 +1,129 @@
+package protect
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Initializer returns the initial value of a field.
+// v is a pointer to the struct containing the field after the copy,
+// so initial values can be derived from other fields, e.g. a slug from the name.
+// The returned value is converted to the type of the field.
+type Initializer func(v interface{}) (interface{}, error)
+
+// initializerKey is the key of Initializer registered with RegisterInitializer.
+type initializerKey struct {
+	typ   reflect.Type
+	field string
+}
+
+// RegisterInitializer registers the initializer of the field of the struct type of v to DefaultProtector.
+// See Protector.RegisterInitializer for details.
+func RegisterInitializer(v interface{}, field string, initializer Initializer) {
+	DefaultProtector.RegisterInitializer(v, field, initializer)
+}
+
+// RegisterInitializer registers the initializer of the field of the struct type of v,
+// e.g. RegisterInitializer(&Client{}, "APIKey", newAPIKey).
+// After a successful Copy or CopySlice, the field is set to the value returned by the initializer
+// if it is protected for the tag and still zero, that is, for newly created values and elements.
+// Initializers run after stamping, so they can use stamped fields.
+// Errors from the initializer are returned from the copy with the path of the field,
+// and dst may be partially initialized then.
+// Registering an initializer for the same field replaces the previous one.
+// It panics if the type has no such field.
+func (p *Protector) RegisterInitializer(v interface{}, field string, initializer Initializer) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("protect: initializers must be registered for structs, got %T", v))
+	}
+	if _, ok := t.FieldByName(field); !ok {
+		panic(fmt.Sprintf("protect: %s has no field %s", t, field))
+	}
+
+	p.initializers.Store(initializerKey{typ: t, field: field}, initializer)
+	p.hasInitializers.Store(true)
+}
+
+// initialize sets zero fields in v protected for the tag of the operation with their initializers.
+func (p *Protector) initialize(s *copyState, v reflect.Value) error {
+	if s.tag == "" || !p.hasInitializers.Load() {
+		return nil
+	}
+	return p.initializeValue(s, v, "", nil, map[uintptr]bool{})
+}
+
+// initializeValue initializes fields in v at path, skipping pointers in visited.
+// promoted holds tags for promoted fields attached by the embedding struct.
+func (p *Protector) initializeValue(s *copyState, v reflect.Value, path string, promoted map[string]string, visited map[uintptr]bool) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || visited[v.Pointer()] {
+			return nil
+		}
+		visited[v.Pointer()] = true
+		return p.initializeValue(s, v.Elem(), path, promoted, visited)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := p.initializeValue(s, v.Index(i), joinPath(path, "[]"), nil, visited); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			// Map values are not addressable, so initialize a copy and put it back
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := p.initializeValue(s, elem, joinPath(path, "[]"), nil, visited); err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	case reflect.Struct:
+		if s.isPrimitiveStruct(v.Type()) {
+			return nil
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			// Shallow fields and fields protected for the tag may be shared with src or untouched by the copy
+			if !field.IsExported() || hasOption(field.Tag.Get(p.optTagName), "shallow") || isProtected(p.promotedProtectionTagValue(field, promoted), s.tag) {
+				continue
+			}
+			if err := p.initializeValue(s, v.Field(i), joinPath(path, field.Name), p.embeddedPromotedTagValues(field), visited); err != nil {
+				return err
+			}
+		}
+
+		// Fields of this struct are initialized after the fields in them
+		if !v.CanAddr() {
+			return nil
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			loaded, ok := p.initializers.Load(initializerKey{typ: t, field: field.Name})
+			if !ok || !v.Field(i).IsZero() || !v.Field(i).CanSet() || !isProtected(p.promotedProtectionTagValue(field, promoted), s.tag) {
+				continue
+			}
+			fieldPath := joinPath(path, field.Name)
+			value, err := loaded.(Initializer)(v.Addr().Interface())
+			if err != nil {
+				return fmt.Errorf("initializing %s: %w", fieldPath, err)
+			}
+			initVal := reflect.ValueOf(value)
+			if !initVal.IsValid() {
+				continue
+			}
+			if !initVal.Type().ConvertibleTo(field.Type) {
+				return fmt.Errorf("initializing %s: cannot use %s as %s", fieldPath, initVal.Type(), field.Type)
+			}
+			s.debugf(fieldPath, "initialized")
+			v.Field(i).Set(initVal.Convert(field.Type))
+		}
+	}
+	return nil
+}
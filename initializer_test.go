@@ -0,0 +1,119 @@
+package protect
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Slug string
+
+type Page struct {
+	Slug   Slug   `protectfor:"create,update"`
+	APIKey string `protectfor:"create,update"`
+	Name   string
+}
+
+type Site struct {
+	Pages []Page
+	Index map[string]Page
+}
+
+func TestInitializers(t *testing.T) {
+	p := NewProtector("protectfor", "protectopt")
+	p.RegisterInitializer(&Page{}, "Slug", func(v interface{}) (interface{}, error) {
+		return strings.ToLower(v.(*Page).Name), nil
+	})
+	keys := 0
+	p.RegisterInitializer(&Page{}, "APIKey", func(v interface{}) (interface{}, error) {
+		keys++
+		return "key" + strings.Repeat("!", keys), nil
+	})
+
+	t.Run("create", func(t *testing.T) {
+		keys = 0
+		var dst Page
+		err := p.Copy("create", &Page{Slug: "client", APIKey: "client", Name: "Home"}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, Page{Slug: "home", APIKey: "key!", Name: "Home"}, dst)
+	})
+
+	t.Run("existing values are kept", func(t *testing.T) {
+		keys = 0
+		dst := Page{Slug: "old", APIKey: "old", Name: "Old"}
+		err := p.Copy("update", &Page{Name: "New"}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, Page{Slug: "old", APIKey: "old", Name: "New"}, dst)
+		assert.Equal(t, 0, keys)
+	})
+
+	t.Run("not protected for the tag", func(t *testing.T) {
+		var dst Page
+		err := p.Copy("other", &Page{Name: "Home"}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, Page{Name: "Home"}, dst)
+	})
+
+	t.Run("new elements", func(t *testing.T) {
+		keys = 0
+		var dst Site
+		src := Site{
+			Pages: []Page{{Name: "A"}, {Name: "B"}},
+			Index: map[string]Page{"c": {Name: "C"}},
+		}
+		err := p.Copy("update", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, []Page{{Slug: "a", APIKey: "key!", Name: "A"}, {Slug: "b", APIKey: "key!!", Name: "B"}}, dst.Pages)
+		assert.Equal(t, Page{Slug: "c", APIKey: "key!!!", Name: "C"}, dst.Index["c"])
+	})
+
+	t.Run("promoted fields", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		p.RegisterInitializer(&AuditFields{}, "ID", func(v interface{}) (interface{}, error) {
+			return "generated", nil
+		})
+		var dst PromotedTagStruct
+		err := p.Copy("create", &PromotedTagStruct{AuditFields: AuditFields{ID: "client", Note: "note"}}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, PromotedTagStruct{AuditFields: AuditFields{ID: "generated", Note: "note"}}, dst)
+	})
+
+	t.Run("shallow and protected fields", func(t *testing.T) {
+		type Holder struct {
+			Shared *Page `protectopt:"shallow"`
+			Locked *Page `protectfor:"update"`
+		}
+		shared := &Page{Name: "Shared"}
+		locked := &Page{Name: "Locked"}
+		dst := Holder{Locked: locked}
+		err := p.Copy("update", &Holder{Shared: shared}, &dst)
+		assert.NoError(t, err)
+		assert.Same(t, shared, dst.Shared)
+		assert.Equal(t, &Page{Name: "Shared"}, shared)
+		assert.Equal(t, &Page{Name: "Locked"}, locked)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		p.RegisterInitializer(&Page{}, "APIKey", func(v interface{}) (interface{}, error) {
+			return nil, errors.New("no entropy")
+		})
+		var dst []Page
+		err := p.CopySlice("create", &[]Page{{Name: "A"}}, &dst, "overwrite")
+		assert.ErrorContains(t, err, "initializing [].APIKey: no entropy")
+
+		p.RegisterInitializer(&Page{}, "APIKey", func(v interface{}) (interface{}, error) {
+			return 1.5, nil
+		})
+		err = p.Copy("create", &Page{}, &Page{})
+		assert.ErrorContains(t, err, "cannot use float64 as string")
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		assert.Panics(t, func() {
+			p.RegisterInitializer(&Page{}, "Missing", nil)
+		})
+	})
+}
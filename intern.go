@@ -0,0 +1,26 @@
+package protect
+
+import (
+	"sync"
+)
+
+// InternTable holds canonical instances of strings shared between operations with WithInterning.
+// It is safe for concurrent use by multiple goroutines.
+// Strings are never removed from the table, so use it for strings from a limited set of values.
+type InternTable struct {
+	strings sync.Map
+}
+
+// NewInternTable creates a new empty InternTable.
+func NewInternTable() *InternTable {
+	return &InternTable{}
+}
+
+// Intern returns the canonical instance of the string equal to s.
+func (t *InternTable) Intern(s string) string {
+	if canonical, ok := t.strings.Load(s); ok {
+		return canonical.(string)
+	}
+	canonical, _ := t.strings.LoadOrStore(s, s)
+	return canonical.(string)
+}
@@ -0,0 +1,47 @@
+package protect
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterning(t *testing.T) {
+	// Build identical strings with distinct memory
+	newStatus := func() string {
+		return string([]byte("active"))
+	}
+	src := &SliceHolder{
+		Items: []SimpleStruct{{Name: newStatus()}, {Name: newStatus()}},
+	}
+	assert.NotSame(t, unsafe.StringData(src.Items[0].Name), unsafe.StringData(src.Items[1].Name))
+
+	t.Run("per call", func(t *testing.T) {
+		clone, _, err := CloneWithStats(src, WithInterning(nil))
+		assert.NoError(t, err)
+		items := clone.(*SliceHolder).Items
+		assert.Equal(t, src.Items, items)
+		assert.Same(t, unsafe.StringData(items[0].Name), unsafe.StringData(items[1].Name))
+	})
+
+	t.Run("shared table", func(t *testing.T) {
+		table := NewInternTable()
+		canonical := table.Intern(newStatus())
+
+		dst := SliceHolder{}
+		err := Copy("update", src, &dst, WithInterning(table))
+		assert.NoError(t, err)
+		assert.Same(t, unsafe.StringData(canonical), unsafe.StringData(dst.Items[0].Name))
+		assert.Same(t, unsafe.StringData(canonical), unsafe.StringData(dst.Items[1].Name))
+	})
+
+	t.Run("slices of strings", func(t *testing.T) {
+		values := []string{newStatus(), newStatus()}
+		clone, _, err := CloneWithStats(values, WithInterning(nil))
+		assert.NoError(t, err)
+		cloned := clone.([]string)
+		assert.Equal(t, values, cloned)
+		assert.Same(t, unsafe.StringData(cloned[0]), unsafe.StringData(cloned[1]))
+	})
+}
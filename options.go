@@ -0,0 +1,465 @@
+package protect
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Option customizes the behavior of a single call of Copy or CopySlice.
+// Options never modify the Protector, so they are safe to use with a Protector shared between goroutines.
+type Option func(s *copyState)
+
+// copyState holds the state of a single copy operation.
+type copyState struct {
+	// protector is the Protector performing the copy.
+	protector *Protector
+	// tag is the tag to protect fields.
+	tag string
+	// path is the path of the value being copied.
+	// Struct fields are separated by dots, and elements of slices, arrays and maps are denoted by "[]".
+	// The root value has the empty path.
+	path string
+	// fieldOptions is the value of the option tag of the field at fieldPath.
+	fieldOptions string
+	// fieldPath is the path of the field being copied.
+	fieldPath string
+	// sliceOptions holds slice options by paths.
+	sliceOptions map[string]string
+	// mapOptions holds map options by paths.
+	mapOptions map[string]string
+	// keepKeys holds keys kept from deletion by the "sync" map option by paths of maps.
+	keepKeys map[string][]interface{}
+	// guards holds paths of guarded fields.
+	guards map[string]bool
+	// unguarded is true while copying new elements, which have no values to guard.
+	unguarded bool
+	// rules holds rules added with WithRule by paths of fields.
+	rules map[string][]rule
+	// versionPath is the path of the version field.
+	versionPath string
+	// clock returns the current time for stamping.
+	clock func() time.Time
+	// stampTime is the time to stamp fields in this operation, set at the first stamp.
+	stampTime time.Time
+	// cloning is true while cloning values, which never checks transitions
+	// and copies every element regardless of slice and map options.
+	cloning bool
+	// writableOnly leaves fields protected for the tag zero, even in cloned elements.
+	writableOnly bool
+	// budget is the maximum number of values to visit. Zero for no limit.
+	budget int
+	// stats holds the statistics of the operation.
+	stats CloneStats
+	// err is the error aborting the operation, reported even where errors cannot be returned.
+	err error
+	// intern returns the canonical instance of a string. Nil not to intern strings.
+	intern func(string) string
+	// replaceMapPointers replaces pointer map values instead of merging into them.
+	replaceMapPointers bool
+	// promoted holds tags for promoted fields attached by the embedding struct,
+	// passed to the embedded struct copied next.
+	promoted map[string]string
+	// ancestors holds copies of pointers being copied, to detect cycles.
+	ancestors map[pointerKey]reflect.Value
+	// debug is the writer to trace the copy walk. Nil not to trace.
+	debug io.Writer
+	// depth is the nesting depth of the current path, used to indent traces.
+	depth int
+	// primitiveStructs overrides the primitive struct registry of the Protector.
+	// true to treat the type as primitive, false not to.
+	primitiveStructs map[reflect.Type]bool
+}
+
+// newCopyState creates a copyState for a copy operation with the tag and options.
+func (p *Protector) newCopyState(tag string, opts []Option) *copyState {
+	s := &copyState{
+		protector: p,
+		tag:       tag,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// enter moves the current path to the child name and returns a function to move back.
+func (s *copyState) enter(name string) func() {
+	parent := s.path
+	s.path = joinPath(parent, name)
+	s.depth++
+	return func() {
+		s.path = parent
+		s.depth--
+	}
+}
+
+// enterField moves the current path to the struct field with the value of its option tag,
+// and returns a function to move back.
+func (s *copyState) enterField(name, options string) func() {
+	parentOptions, parentPath := s.fieldOptions, s.fieldPath
+	leave := s.enter(name)
+	s.fieldOptions, s.fieldPath = options, s.path
+	return func() {
+		leave()
+		s.fieldOptions, s.fieldPath = parentOptions, parentPath
+	}
+}
+
+// fieldOption returns the option among candidates in the option tag of the field at the current path.
+// Options of a field apply only to the value of the field itself, not to its elements.
+func (s *copyState) fieldOption(candidates ...string) (string, bool) {
+	if s.fieldOptions == "" || s.fieldPath != s.path {
+		return "", false
+	}
+	for _, candidate := range candidates {
+		if hasOption(s.fieldOptions, candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// fieldOptionWithValue returns the option with a value like "name=value" in the option tag of the field at the current path.
+// Options of a field apply only to the value of the field itself, not to its elements.
+func (s *copyState) fieldOptionWithValue(name string) (string, bool) {
+	if s.fieldOptions == "" || s.fieldPath != s.path {
+		return "", false
+	}
+	for _, o := range strings.Split(s.fieldOptions, ",") {
+		o = strings.TrimSpace(o)
+		if strings.HasPrefix(o, name+"=") {
+			return o, true
+		}
+	}
+	return "", false
+}
+
+// joinPath joins a field name or "[]" for elements to the parent path.
+func joinPath(parent, name string) string {
+	if parent == "" || name == "[]" {
+		return parent + name
+	}
+	return parent + "." + name
+}
+
+// setSliceOption sets the slice option for the slice at the path.
+func (s *copyState) setSliceOption(path, option string) {
+	if s.sliceOptions == nil {
+		s.sliceOptions = make(map[string]string)
+	}
+	s.sliceOptions[path] = option
+}
+
+// setMapOption sets the map option for the map at the path.
+func (s *copyState) setMapOption(path, option string) {
+	if s.mapOptions == nil {
+		s.mapOptions = make(map[string]string)
+	}
+	s.mapOptions[path] = option
+}
+
+// WithSliceOption sets the slice option for the slice at the path in this call,
+// e.g. WithSliceOption("Items", "match").
+// See CopySlice for available options; the empty path is the root value.
+// It takes precedence over the `protectopt` tag of the field.
+func WithSliceOption(path, option string) Option {
+	return func(s *copyState) {
+		s.setSliceOption(path, option)
+	}
+}
+
+// WithMapOption sets the map option for the map at the path in this call,
+// e.g. WithMapOption("Labels", "patch").
+// Available options are "overwrite", "match", "patch" and "sync".
+// It takes precedence over the `protectopt` tag of the field.
+func WithMapOption(path, option string) Option {
+	return func(s *copyState) {
+		s.setMapOption(path, option)
+	}
+}
+
+// WithKeepKeys keeps the keys of the map at the path from deletion by the "sync" map option in this call,
+// e.g. WithKeepKeys("Labels", "managed-by") to keep server-managed entries even when the client omits them.
+func WithKeepKeys(path string, keys ...interface{}) Option {
+	return func(s *copyState) {
+		if s.keepKeys == nil {
+			s.keepKeys = make(map[string][]interface{})
+		}
+		s.keepKeys[path] = append(s.keepKeys[path], keys...)
+	}
+}
+
+// containsValue checks if values contains a value matching v with matchesValue.
+func containsValue(values []interface{}, v reflect.Value) bool {
+	for _, value := range values {
+		if matchesValue(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithGuard guards the fields at the paths, e.g. "TenantID" or "Items[].OwnerID".
+// A guarded field must be zero in src or equal in src and dst,
+// otherwise the copy fails with *GuardError.
+// A zero guarded field in src leaves the field in dst as is.
+// This prevents cross-tenant or cross-owner reassignment
+// even when a protection tag is missing on a new write path.
+// Elements of slices and maps are guarded only when they are copied onto existing elements
+// (e.g. "match" mode); new elements, such as ones appended in "match" mode, are not guarded,
+// as there is nothing to compare them with.
+// Note that dst may be partially updated when the copy fails; use CopyPure to avoid that.
+func WithGuard(paths ...string) Option {
+	return func(s *copyState) {
+		if s.guards == nil {
+			s.guards = make(map[string]bool)
+		}
+		for _, path := range paths {
+			s.guards[path] = true
+		}
+	}
+}
+
+// WithVersion uses the integer field at the path (e.g. "Version") for optimistic locking.
+// The version in src must be equal to the version in dst, otherwise the copy fails with ErrVersionConflict.
+// The version in dst is incremented by the copy, whether the field is protected or not.
+// The copy fails without modifying dst if the path doesn't point to a field,
+// so typos and renamed fields never disable optimistic locking silently.
+// Note that dst may be partially updated when the copy fails; use CopyPure to avoid that.
+func WithVersion(path string) Option {
+	return func(s *copyState) {
+		s.versionPath = path
+	}
+}
+
+// checkVersionPath checks the path set with WithVersion points to a field of values of t.
+func (s *copyState) checkVersionPath(t reflect.Type) error {
+	if s.versionPath == "" || hasFieldPath(t, s.versionPath) {
+		return nil
+	}
+	return fmt.Errorf("version field %s not found in %s", s.versionPath, t)
+}
+
+// hasFieldPath checks if the path (e.g. "Items[].Version") points to an exported field of values of t.
+// Paths through interfaces are not checked, as the types of their values are unknown.
+func hasFieldPath(t reflect.Type, path string) bool {
+	if path == "" || strings.HasSuffix(path, "[]") {
+		return false
+	}
+	for path != "" {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Interface {
+			return true
+		}
+
+		if strings.HasPrefix(path, "[]") {
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+				t = t.Elem()
+			default:
+				return false
+			}
+			path = strings.TrimPrefix(path[len("[]"):], ".")
+			continue
+		}
+
+		name, rest := path, ""
+		if i := strings.IndexAny(path, ".["); i >= 0 {
+			name, rest = path[:i], strings.TrimPrefix(path[i:], ".")
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		field, ok := t.FieldByName(name)
+		if !ok || len(field.Index) != 1 || !field.IsExported() {
+			return false
+		}
+		t, path = field.Type, rest
+	}
+	return true
+}
+
+// WithClock uses now instead of the Clock of the Protector or time.Now
+// to stamp fields with `protectstamp` tags in this call.
+func WithClock(now func() time.Time) Option {
+	return func(s *copyState) {
+		s.clock = now
+	}
+}
+
+// now returns the current time for stamping.
+func (s *copyState) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	if clock := s.protector.getClock(); clock != nil {
+		return clock.Now()
+	}
+	return time.Now()
+}
+
+// WithDebugWriter writes an indented trace of the copy walk in this call to w,
+// one line per decision with the path, e.g. `Items[].ID: protected for "update", skipped`.
+// This is for debugging unexpected merges of nested values; the format is not stable.
+// Errors writing to w are ignored.
+func WithDebugWriter(w io.Writer) Option {
+	return func(s *copyState) {
+		s.debug = w
+	}
+}
+
+// debugf writes a trace line for the value at the path if WithDebugWriter is specified.
+func (s *copyState) debugf(path, format string, args ...interface{}) {
+	if s.debug == nil {
+		return
+	}
+	fmt.Fprintf(s.debug, "%s%s: %s\n", strings.Repeat("  ", s.depth), displayPath(path), fmt.Sprintf(format, args...))
+}
+
+// ErrBudgetExceeded is returned when an operation visits more values than the budget set with WithBudget.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// CloneStats holds the statistics of a clone or copy operation.
+type CloneStats struct {
+	// Values is the number of values visited, including structs, their fields and elements.
+	// Slices and arrays of plain values such as numbers are copied at once and count as one value.
+	Values int
+	// Allocations is the number of pointers, slices and maps allocated.
+	Allocations int
+}
+
+// WithBudget aborts the operation with ErrBudgetExceeded when it visits more than max values,
+// protecting caching layers and handlers from excessively large aggregates.
+// dst may be partially updated when the operation is aborted.
+func WithBudget(max int) Option {
+	return func(s *copyState) {
+		s.budget = max
+	}
+}
+
+// visit counts a value visited in the operation and reports whether the operation can continue.
+func (s *copyState) visit() bool {
+	if s.err != nil {
+		return false
+	}
+	s.stats.Values++
+	if s.budget > 0 && s.stats.Values > s.budget {
+		s.err = fmt.Errorf("%w: more than %d values", ErrBudgetExceeded, s.budget)
+		return false
+	}
+	return true
+}
+
+// allocated counts an allocation in the operation.
+func (s *copyState) allocated() {
+	s.stats.Allocations++
+}
+
+// WithInterning interns strings copied in this call with the table,
+// so that identical strings share their memory,
+// e.g. to cut memory for large slices of records with repetitive status or country codes.
+// If table is nil, strings are interned only within this call.
+// Strings in primitive structs and map keys are not interned.
+func WithInterning(table *InternTable) Option {
+	return func(s *copyState) {
+		if table != nil {
+			s.intern = table.Intern
+			return
+		}
+		canonicals := make(map[string]string)
+		s.intern = func(str string) string {
+			if canonical, ok := canonicals[str]; ok {
+				return canonical
+			}
+			canonicals[str] = str
+			return str
+		}
+	}
+}
+
+// internString returns the string to copy for str.
+func (s *copyState) internString(str string) string {
+	if s.intern == nil {
+		return str
+	}
+	return s.intern(str)
+}
+
+// WithMapPointerReplace replaces pointer values of maps with new pointers in "match" and "patch" modes.
+// By default, source values are merged into the values pointed by existing pointers in place,
+// so that other references to them see the update.
+// With this option, existing values are cloned and the source values are merged into the clones,
+// leaving values pointed by the original pointers untouched.
+// Protected fields are preserved in both cases.
+func WithMapPointerReplace() Option {
+	return func(s *copyState) {
+		s.replaceMapPointers = true
+	}
+}
+
+// isPrimitiveStruct checks if a type should be treated as a primitive value in this operation.
+func (s *copyState) isPrimitiveStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if primitive, ok := s.primitiveStructs[t]; ok {
+		return primitive
+	}
+	return s.protector.IsPrimitiveStruct(t)
+}
+
+// isPlainType checks if values of t are copied by simple assignment in this operation,
+// so that slices and arrays of them can be copied at once.
+func (s *copyState) isPlainType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String:
+		// Strings are copied one by one to intern them
+		return s.intern == nil
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	case reflect.Array:
+		return s.isPlainType(t.Elem())
+	case reflect.Struct:
+		return s.isPrimitiveStruct(t)
+	}
+	return false
+}
+
+// WithPrimitiveStruct treats the struct type of v as a primitive value in this call,
+// as Protector.AddPrimitiveStruct does.
+func WithPrimitiveStruct(v interface{}) Option {
+	return withPrimitiveStruct(v, true)
+}
+
+// WithoutPrimitiveStruct walks the struct type of v field by field in this call,
+// even if it is registered as a primitive struct in the Protector.
+func WithoutPrimitiveStruct(v interface{}) Option {
+	return withPrimitiveStruct(v, false)
+}
+
+// withPrimitiveStruct overrides primitive struct treatment of the type of v.
+func withPrimitiveStruct(v interface{}, primitive bool) Option {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return func(s *copyState) {
+		// Only struct types can be primitive structs
+		if t.Kind() != reflect.Struct {
+			return
+		}
+		if s.primitiveStructs == nil {
+			s.primitiveStructs = make(map[reflect.Type]bool)
+		}
+		s.primitiveStructs[t] = primitive
+	}
+}
@@ -0,0 +1,328 @@
+package protect
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type VendorStruct struct {
+	ID   string `protectfor:"update"`
+	Name string
+}
+
+type VendorHolder struct {
+	Vendor    VendorStruct
+	CreatedAt time.Time
+}
+
+func TestPrimitiveStructOptions(t *testing.T) {
+	src := VendorHolder{
+		Vendor:    VendorStruct{ID: "1", Name: "Vendor"},
+		CreatedAt: time.Now(),
+	}
+
+	t.Run("with primitive struct", func(t *testing.T) {
+		p := createTestProtector()
+		dst := VendorHolder{}
+
+		err := p.Copy("update", &src, &dst, WithPrimitiveStruct(&VendorStruct{}))
+		assert.NoError(t, err)
+
+		// Copied as a whole, ignoring protection
+		assert.Equal(t, src.Vendor, dst.Vendor)
+
+		// The Protector is not modified
+		assert.False(t, p.IsPrimitiveStruct(reflect.TypeOf(VendorStruct{})))
+		dst = VendorHolder{}
+		err = p.Copy("update", &src, &dst)
+		assert.NoError(t, err)
+		assert.Empty(t, dst.Vendor.ID)
+	})
+
+	t.Run("without primitive struct", func(t *testing.T) {
+		p := createTestProtector()
+		dst := VendorHolder{}
+
+		err := p.Copy("update", &src, &dst, WithoutPrimitiveStruct(time.Time{}))
+		assert.NoError(t, err)
+
+		// time.Time is walked field by field and has no exported fields
+		assert.True(t, dst.CreatedAt.IsZero())
+		assert.Equal(t, "Vendor", dst.Vendor.Name)
+
+		// The Protector is not modified
+		assert.True(t, p.IsPrimitiveStruct(reflect.TypeOf(time.Time{})))
+	})
+
+	t.Run("copy slice", func(t *testing.T) {
+		srcSlice := []VendorStruct{{ID: "1", Name: "Vendor"}}
+		dstSlice := []VendorStruct{{ID: "X", Name: "Existing"}}
+
+		err := CopySlice("update", &srcSlice, &dstSlice, "match", WithPrimitiveStruct(VendorStruct{}))
+		assert.NoError(t, err)
+		assert.Equal(t, "1", dstSlice[0].ID)
+	})
+}
+
+type SliceHolder struct {
+	Items   []SimpleStruct
+	Holders []SliceHolder
+}
+
+func TestCollectionOptionsByPath(t *testing.T) {
+	t.Run("option applies only to the path", func(t *testing.T) {
+		src := SliceHolder{
+			Items: []SimpleStruct{{ID: "1", Name: "First"}},
+			Holders: []SliceHolder{
+				{Items: []SimpleStruct{{ID: "2", Name: "Second"}}},
+			},
+		}
+		dst := SliceHolder{
+			Items: []SimpleStruct{{ID: "X", Name: "Existing"}},
+			Holders: []SliceHolder{
+				{Items: []SimpleStruct{{ID: "Y", Name: "Existing"}}},
+			},
+		}
+
+		err := Copy("create", &src, &dst, WithSliceOption("Holders", "match"), WithSliceOption("Holders[].Items", "match"))
+		assert.NoError(t, err)
+
+		// Items at the root uses the default overwrite option
+		assert.Equal(t, "1", dst.Items[0].ID)
+		// Items in holders use the match option
+		assert.Equal(t, "Y", dst.Holders[0].Items[0].ID)
+		assert.Equal(t, "Second", dst.Holders[0].Items[0].Name)
+	})
+
+	t.Run("options don't remain after the call", func(t *testing.T) {
+		p := createTestProtector()
+		src := SliceHolder{Items: []SimpleStruct{{ID: "1", Name: "First"}}}
+		dst := SliceHolder{Items: []SimpleStruct{{ID: "X", Name: "Existing"}}}
+
+		err := p.Copy("create", &src, &dst, WithSliceOption("Items", "match"))
+		assert.NoError(t, err)
+		assert.Equal(t, "X", dst.Items[0].ID)
+
+		// The same destination slice is copied with the default option
+		err = p.Copy("create", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", dst.Items[0].ID)
+	})
+}
+
+type TenantItem struct {
+	OwnerID string
+	Name    string
+}
+
+type TenantStruct struct {
+	TenantID string
+	Name     string
+	Items    []TenantItem
+}
+
+func TestGuard(t *testing.T) {
+	t.Run("zero and equal values pass", func(t *testing.T) {
+		dst := TenantStruct{TenantID: "t1", Name: "old"}
+
+		err := Copy("update", &TenantStruct{Name: "new"}, &dst, WithGuard("TenantID"))
+		assert.NoError(t, err)
+		assert.Equal(t, TenantStruct{TenantID: "t1", Name: "new"}, dst)
+
+		err = Copy("update", &TenantStruct{TenantID: "t1", Name: "newer"}, &dst, WithGuard("TenantID"))
+		assert.NoError(t, err)
+		assert.Equal(t, TenantStruct{TenantID: "t1", Name: "newer"}, dst)
+	})
+
+	t.Run("mismatch fails", func(t *testing.T) {
+		dst := TenantStruct{TenantID: "t1"}
+
+		err := Copy("update", &TenantStruct{TenantID: "t2"}, &dst, WithGuard("TenantID"))
+		var guardErr *GuardError
+		assert.ErrorAs(t, err, &guardErr)
+		assert.Equal(t, "TenantID", guardErr.Path)
+		assert.Equal(t, "t2", guardErr.Src)
+		assert.Equal(t, "t1", guardErr.Dst)
+		assert.Equal(t, "t1", dst.TenantID)
+	})
+
+	t.Run("elements", func(t *testing.T) {
+		dst := TenantStruct{Items: []TenantItem{{OwnerID: "u1"}}}
+		src := TenantStruct{Items: []TenantItem{{OwnerID: "u2"}}}
+
+		err := Copy("update", &src, &dst, WithGuard("Items[].OwnerID"), WithSliceOption("Items", "match"))
+		var guardErr *GuardError
+		assert.ErrorAs(t, err, &guardErr)
+		assert.Equal(t, "Items[].OwnerID", guardErr.Path)
+
+		// Without existing elements, there is nothing to guard
+		err = Copy("update", &src, &dst, WithGuard("Items[].OwnerID"))
+		assert.NoError(t, err)
+		assert.Equal(t, "u2", dst.Items[0].OwnerID)
+	})
+
+	t.Run("appended elements", func(t *testing.T) {
+		dst := TenantStruct{Items: []TenantItem{{OwnerID: "u1", Name: "old"}}}
+		src := TenantStruct{Items: []TenantItem{{OwnerID: "u1", Name: "new"}, {OwnerID: "u2", Name: "added"}}}
+
+		err := Copy("update", &src, &dst, WithGuard("Items[].OwnerID"), WithSliceOption("Items", "match"))
+		assert.NoError(t, err)
+		assert.Equal(t, []TenantItem{{OwnerID: "u1", Name: "new"}, {OwnerID: "u2", Name: "added"}}, dst.Items)
+
+		dst = TenantStruct{Items: []TenantItem{{OwnerID: "u1", Name: "old"}}}
+		src = TenantStruct{Items: []TenantItem{{OwnerID: "u2", Name: "added"}, {OwnerID: "u1", Name: "new"}}}
+		err = Copy("update", &src, &dst, WithGuard("Items[].OwnerID"), WithSliceOption("Items", "matchbykey=OwnerID"))
+		assert.NoError(t, err)
+		assert.Equal(t, []TenantItem{{OwnerID: "u2", Name: "added"}, {OwnerID: "u1", Name: "new"}}, dst.Items)
+	})
+}
+
+type VersionedStruct struct {
+	ID      string `protectfor:"update"`
+	Name    string
+	Version uint `protectfor:"update"`
+}
+
+func TestVersion(t *testing.T) {
+	t.Run("matching version is bumped", func(t *testing.T) {
+		dst := VersionedStruct{ID: "1", Name: "old", Version: 3}
+
+		err := Copy("update", &VersionedStruct{Name: "new", Version: 3}, &dst, WithVersion("Version"))
+		assert.NoError(t, err)
+		assert.Equal(t, VersionedStruct{ID: "1", Name: "new", Version: 4}, dst)
+	})
+
+	t.Run("stale version fails", func(t *testing.T) {
+		dst := VersionedStruct{ID: "1", Name: "old", Version: 3}
+
+		err := Copy("update", &VersionedStruct{Name: "new", Version: 2}, &dst, WithVersion("Version"))
+		assert.ErrorIs(t, err, ErrVersionConflict)
+		assert.Equal(t, uint(3), dst.Version)
+	})
+
+	t.Run("non-integer version", func(t *testing.T) {
+		dst := VersionedStruct{}
+
+		err := Copy("update", &VersionedStruct{}, &dst, WithVersion("Name"))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrVersionConflict)
+	})
+
+	t.Run("unknown version field", func(t *testing.T) {
+		dst := VersionedStruct{ID: "1", Name: "old", Version: 3}
+
+		err := Copy("update", &VersionedStruct{Name: "new", Version: 2}, &dst, WithVersion("Revision"))
+		assert.ErrorContains(t, err, "version field Revision not found")
+		assert.Equal(t, VersionedStruct{ID: "1", Name: "old", Version: 3}, dst)
+
+		items := []VersionedStruct{{Version: 1}}
+		err = CopySlice("update", &[]VersionedStruct{{Version: 1}}, &items, "match", WithVersion("[].Version"))
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), items[0].Version)
+
+		err = CopySlice("update", &[]VersionedStruct{}, &items, "match", WithVersion("[].Versions"))
+		assert.ErrorContains(t, err, "version field [].Versions not found")
+	})
+}
+
+type MatrixCell struct {
+	Value float64
+	Flag  bool `protectfor:"update"`
+}
+
+type MatrixHolder struct {
+	Cells  []MatrixCell
+	Grid   [2][2]MatrixCell
+	Values []float64
+}
+
+func TestPlainValuesCopiedAtOnce(t *testing.T) {
+	src := MatrixHolder{
+		Cells:  []MatrixCell{{Value: 1, Flag: true}},
+		Grid:   [2][2]MatrixCell{{{Value: 2, Flag: true}}},
+		Values: []float64{3, 4},
+	}
+
+	t.Run("primitive struct elements", func(t *testing.T) {
+		p := createTestProtector()
+		p.AddPrimitiveStruct(&MatrixCell{})
+
+		clone := p.Clone(&src).(*MatrixHolder)
+		assert.Equal(t, src, *clone)
+
+		// Buffers are not shared
+		clone.Cells[0].Value = 10
+		clone.Values[0] = 30
+		assert.Equal(t, 1.0, src.Cells[0].Value)
+		assert.Equal(t, 3.0, src.Values[0])
+
+		// Primitive structs ignore tags
+		dst := MatrixHolder{}
+		err := p.Copy("update", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, src, dst)
+	})
+
+	t.Run("other struct elements are copied element by element", func(t *testing.T) {
+		dst := MatrixHolder{}
+		err := Copy("update", &src, &dst)
+		assert.NoError(t, err)
+		assert.False(t, dst.Grid[0][0].Flag)
+		assert.Equal(t, 2.0, dst.Grid[0][0].Value)
+	})
+}
+
+func TestCloneWithStats(t *testing.T) {
+	src := &SliceHolder{
+		Items: []SimpleStruct{{ID: "1"}, {ID: "2"}},
+	}
+
+	clone, stats, err := CloneWithStats(src)
+	assert.NoError(t, err)
+	assert.Equal(t, src, clone)
+	// SliceHolder, its 2 fields, 2 elements and their 3 fields each
+	assert.Equal(t, 11, stats.Values)
+	// The root and the Items slice
+	assert.Equal(t, 2, stats.Allocations)
+
+	t.Run("budget", func(t *testing.T) {
+		_, _, err := CloneWithStats(src, WithBudget(11))
+		assert.NoError(t, err)
+
+		clone, stats, err := CloneWithStats(src, WithBudget(5))
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+		assert.Nil(t, clone)
+		assert.Equal(t, 6, stats.Values)
+
+		dst := SliceHolder{}
+		err = Copy("update", src, &dst, WithBudget(5))
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+	})
+}
+
+func TestDebugWriter(t *testing.T) {
+	src := TaggedMapOptions{
+		Patched: map[string]SimpleStruct{"a": {ID: "N1", Name: "New"}},
+	}
+	dst := TaggedMapOptions{
+		Patched: map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}},
+	}
+
+	var b strings.Builder
+	err := Copy("update", &src, &dst, WithDebugWriter(&b))
+	assert.NoError(t, err)
+	assert.Equal(t, `Patched: copying
+  Patched: map option "patch" (src 1, dst 1 keys)
+    Patched[]: key a merged into the existing value
+    Patched[].ID: protected for "update", skipped
+    Patched[].Code: protected for "update", skipped
+    Patched[].Name: copying
+Matched: copying
+Overwrite: copying
+Default: copying
+`, b.String())
+}
@@ -1,28 +1,110 @@
 package protect
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 // Protector is the struct to customize the behavior of protect.
+// A Protector is safe for concurrent use by multiple goroutines.
+// The state of each operation, including options passed to Copy and CopySlice,
+// is held per call and never shared between calls.
 type Protector struct {
 	// tagName is the tag name to specify fields to be protected.
 	tagName string
 	// optTagName is the tag name to specify options for protection.
 	optTagName string
 
-	// For testing: maps to override options
-	sliceOptions sync.Map
-	mapOptions   sync.Map
-
 	// primitiveStructs is a map to store types that should be treated as primitive values
 	primitiveStructs sync.Map
+
+	// assignConvertible allows copying between distinct types with identical underlying types
+	assignConvertible atomic.Bool
+
+	// clock holds the clockValue to stamp fields
+	clock atomic.Value
+	// idGenerator holds the idGeneratorValue to stamp ID fields
+	idGenerator atomic.Value
+
+	// combineEmbeddedTags combines tags for promoted fields from embedding structs with their own tags
+	combineEmbeddedTags atomic.Bool
+
+	// allowlistTag holds the name of the tag listing tags fields are copied for in the allowlist mode
+	allowlistTag atomic.Value
+
+	// accessors holds functions to read sources of other types, keyed by accessorKey
+	accessors sync.Map
+
+	// transitions holds Transitions keyed by transitionKey
+	transitions sync.Map
+	// hasTransitions is true if any Transitions are registered
+	hasTransitions atomic.Bool
+
+	// initializers holds Initializer keyed by initializerKey
+	initializers sync.Map
+	// hasInitializers is true if any Initializer is registered
+	hasInitializers atomic.Bool
+
+	// cyclePolicy holds the CyclePolicy
+	cyclePolicy atomic.Int32
+}
+
+// ErrDstNotPointer is returned when dst is not a pointer to the destination value.
+var ErrDstNotPointer = errors.New("dst must be a pointer")
+
+// GuardError is returned when a field guarded with WithGuard differs between src and dst.
+type GuardError struct {
+	// Path is the path of the guarded field, e.g. "TenantID".
+	Path string
+	// Src is the value of the field in src.
+	Src interface{}
+	// Dst is the value of the field in dst.
+	Dst interface{}
+}
+
+// Error returns the description of the error.
+func (e *GuardError) Error() string {
+	return fmt.Sprintf("guarded field %s cannot be changed from %v to %v", e.Path, e.Dst, e.Src)
+}
+
+// ErrVersionConflict is returned when the version field specified with WithVersion differs between src and dst.
+var ErrVersionConflict = errors.New("version conflict")
+
+// bumpVersion verifies the version in src matches the version in dst, and increments the version in dst.
+func bumpVersion(path string, src, dst reflect.Value) error {
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if src.Int() != dst.Int() {
+			return fmt.Errorf("%w: %s is %d, but %d is expected", ErrVersionConflict, path, src.Int(), dst.Int())
+		}
+		dst.SetInt(dst.Int() + 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if src.Uint() != dst.Uint() {
+			return fmt.Errorf("%w: %s is %d, but %d is expected", ErrVersionConflict, path, src.Uint(), dst.Uint())
+		}
+		dst.SetUint(dst.Uint() + 1)
+	default:
+		return fmt.Errorf("version field %s must be an integer, got %s", path, src.Type())
+	}
+	return nil
 }
 
+// Copier is the interface of the copy operations provided by Protector.
+// Accept Copier instead of *Protector to replace it with a fake in tests.
+type Copier interface {
+	Copy(tag string, src, dst interface{}, opts ...Option) error
+	Clone(src interface{}) interface{}
+	CopySlice(tag string, src, dst interface{}, option string, opts ...Option) error
+	CopyMap(tag string, src, dst interface{}, option string, opts ...Option) error
+}
+
+var _ Copier = (*Protector)(nil)
+
 // DefaultProtector is the default Protector instance used by package level functions.
 var DefaultProtector = NewProtector("protectfor", "protectopt")
 
@@ -41,6 +123,9 @@ func NewProtector(tagName, optTagName string) *Protector {
 
 // AddPrimitiveStruct registers a struct type to be treated as a primitive value when copying.
 // This means the struct will be copied by direct assignment rather than field-by-field.
+// Slices and arrays of primitive structs are also copied at once rather than element-by-element,
+// so registering plain data types (e.g. vectors or matrix cells without pointers)
+// speeds up cloning large buffers of them significantly.
 func (p *Protector) AddPrimitiveStruct(v interface{}) {
 	t := reflect.TypeOf(v)
 
@@ -67,17 +152,61 @@ func (p *Protector) IsPrimitiveStruct(t reflect.Type) bool {
 	return ok
 }
 
+// SetAssignConvertible enables or disables the "assign convertible" mode.
+// By default, src and dst must be the same type.
+// In the assign convertible mode, src is converted to the type of dst
+// when they are distinct named types with the same underlying type
+// (e.g. `type UserID string` and `string`, or `type Items []Item` and `[]Item`).
+// Protection rules are applied with the tags of the dst type.
+func (p *Protector) SetAssignConvertible(enabled bool) {
+	p.assignConvertible.Store(enabled)
+}
+
+// SetCombineEmbeddedTags enables or disables combining tags for promoted fields.
+// Tags for promoted fields on embedded fields (e.g. `protectfor:"ID=create|update"`)
+// override the protection tags of the fields in the embedded struct by default,
+// so the embedding struct can both add and remove protection (e.g. `protectfor:"ID="`).
+// When enabled, the fields are protected for both their own tags and the tags from the embedding struct.
+func (p *Protector) SetCombineEmbeddedTags(enabled bool) {
+	p.combineEmbeddedTags.Store(enabled)
+}
+
+// SetAllowlistTag enables the allowlist mode with the tag name, e.g. "copyfor".
+// In the allowlist mode, fields are protected for every tag by default,
+// and copied only for the tags listed in the tag, e.g. `copyfor:"create,update"`,
+// which suits security-sensitive APIs denying fields by default.
+// Fields containing fields to copy must be allowed as well, e.g. slices of structs.
+// Protection tags and classes still protect fields listed in the tag.
+// Copies without tags copy all fields as usual.
+// Pass "" to disable the allowlist mode.
+func (p *Protector) SetAllowlistTag(tagName string) {
+	p.allowlistTag.Store(tagName)
+}
+
+// isAssignConvertible checks if a value of src type can be copied to dst type
+// in the assign convertible mode.
+func (p *Protector) isAssignConvertible(src, dst reflect.Type) bool {
+	if !p.assignConvertible.Load() {
+		return false
+	}
+
+	// Reject conversions changing the kind of value (e.g. int to string)
+	return src.Kind() == dst.Kind() && src.ConvertibleTo(dst)
+}
+
 // Copy copies the values from src to dst excluding fields marked with the tag.
 // The tag value should be a comma-separated list of values.
 // If the tag contains the value specified by "tag", the field will be skipped.
-func Copy(tag string, src, dst interface{}) error {
-	return DefaultProtector.Copy(tag, src, dst)
+// opts customize the behavior of this call.
+func Copy(tag string, src, dst interface{}, opts ...Option) error {
+	return DefaultProtector.Copy(tag, src, dst, opts...)
 }
 
 // Copy copies the values from src to dst excluding fields marked with the tag.
 // The tag value should be a comma-separated list of values.
 // If the tag contains the value specified by "tag", the field will be skipped.
-func (p *Protector) Copy(tag string, src, dst interface{}) error {
+// opts customize the behavior of this call.
+func (p *Protector) Copy(tag string, src, dst interface{}, opts ...Option) error {
 	if src == nil || dst == nil {
 		return fmt.Errorf("src and dst must not be nil")
 	}
@@ -85,40 +214,251 @@ func (p *Protector) Copy(tag string, src, dst interface{}) error {
 	srcVal := reflect.ValueOf(src)
 	dstVal := reflect.ValueOf(dst)
 
+	if dstVal.Kind() != reflect.Ptr {
+		return fmt.Errorf("%w, got %s: pass the address of the destination (e.g. &dst)", ErrDstNotPointer, dstVal.Type())
+	}
+
+	if dstVal.IsNil() {
+		return fmt.Errorf("dst must not be nil pointer")
+	}
+
+	dstVal = dstVal.Elem()
+
+	srcVal, err := p.sourceValue(srcVal, dstVal.Type())
+	if err != nil {
+		return err
+	}
+	return p.copyRoot(p.newCopyState(tag, opts), srcVal, dstVal)
+}
+
+// sourceValue returns the value to copy from src to a value of dst type.
+func (p *Protector) sourceValue(srcVal reflect.Value, dst reflect.Type) (reflect.Value, error) {
+	// Read sources of other types with the registered accessor
+	if accessed, ok := p.access(srcVal, dst); ok {
+		srcVal = accessed
+	}
+
 	// Dereference pointers to get the actual value
 	if srcVal.Kind() == reflect.Ptr {
 		if srcVal.IsNil() {
-			return fmt.Errorf("src must not be nil pointer")
+			return reflect.Value{}, fmt.Errorf("src must not be nil pointer")
 		}
 		srcVal = srcVal.Elem()
 	}
 
-	if dstVal.Kind() != reflect.Ptr {
-		return fmt.Errorf("dst must be a pointer")
+	if srcVal.Type() != dst {
+		if dst.Kind() == reflect.Interface && srcVal.Kind() != reflect.Interface {
+			return reflect.Value{}, fmt.Errorf("%w, got pointer to %s: did you mean to pass the pointer held in the interface instead of its address?", ErrDstNotPointer, dst)
+		}
+		if !p.isAssignConvertible(srcVal.Type(), dst) {
+			return reflect.Value{}, fmt.Errorf("src and dst must be the same type, got %s and %s", srcVal.Type(), dst)
+		}
+		srcVal = srcVal.Convert(dst)
+	}
+	return srcVal, nil
+}
+
+// copyRoot copies the root value src to dst in the operation.
+func (p *Protector) copyRoot(s *copyState, srcVal, dstVal reflect.Value) error {
+	if err := s.checkVersionPath(dstVal.Type()); err != nil {
+		return err
+	}
+	// The root is an ancestor of all values in it
+	if srcVal.CanAddr() {
+		defer s.enterPointer(srcVal.Addr(), dstVal.Addr())()
 	}
+	if err := p.copyValue(s, srcVal, dstVal); err != nil {
+		return err
+	}
+	if s.err != nil {
+		return s.err
+	}
+	return p.initialize(s, dstVal)
+}
+
+// CopyPure returns the result of copying src to dst without modifying dst.
+// See Protector.CopyPure for details.
+func CopyPure(tag string, src, dst interface{}, opts ...Option) (interface{}, error) {
+	return DefaultProtector.CopyPure(tag, src, dst, opts...)
+}
 
+// CopyPure returns the result of copying src to dst without modifying dst.
+// dst is cloned and src is copied to the clone as Copy does,
+// so the result is a pointer of the same type as dst.
+// This is useful to compute what an entity would look like before committing the change,
+// e.g. for authorization checks.
+func (p *Protector) CopyPure(tag string, src, dst interface{}, opts ...Option) (interface{}, error) {
+	if src == nil || dst == nil {
+		return nil, fmt.Errorf("src and dst must not be nil")
+	}
+	dstVal := reflect.ValueOf(dst)
+	if dstVal.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("%w, got %s: pass the address of the destination (e.g. &dst)", ErrDstNotPointer, dstVal.Type())
+	}
 	if dstVal.IsNil() {
-		return fmt.Errorf("dst must not be nil pointer")
+		return nil, fmt.Errorf("dst must not be nil pointer")
 	}
 
-	dstVal = dstVal.Elem()
+	result, err := p.cloneWithState(p.newCopyState("", nil), dst)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Copy(tag, src, result, opts...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	if srcVal.Type() != dstVal.Type() {
-		return fmt.Errorf("src and dst must be the same type, got %s and %s", srcVal.Type(), dstVal.Type())
+// NewFromTemplate returns a new value from the template with overrides applied.
+// See Protector.NewFromTemplate for details.
+func NewFromTemplate(tag string, template, overrides interface{}, opts ...Option) (interface{}, error) {
+	return DefaultProtector.NewFromTemplate(tag, template, overrides, opts...)
+}
+
+// NewFromTemplate returns a new value from the template with overrides applied,
+// e.g. for endpoints creating entities with defaults.
+// The template is cloned and overrides are copied to the clone as Copy does,
+// so fields protected for the tag keep the defaults in the template
+// and the template itself is never modified.
+// template must be a pointer, and the result is a pointer of the same type.
+func (p *Protector) NewFromTemplate(tag string, template, overrides interface{}, opts ...Option) (interface{}, error) {
+	if templateVal := reflect.ValueOf(template); templateVal.Kind() == reflect.Ptr && templateVal.IsNil() {
+		return nil, fmt.Errorf("template must not be nil pointer")
+	}
+	return p.CopyPure(tag, overrides, template, opts...)
+}
+
+// CopyFanOut copies src to each of dsts excluding fields marked with the tag.
+// See Protector.CopyFanOut for details.
+func CopyFanOut(tag string, src interface{}, dsts []interface{}, opts ...Option) error {
+	return DefaultProtector.CopyFanOut(tag, src, dsts, opts...)
+}
+
+// CopyFanOut copies src to each of dsts excluding fields marked with the tag as Copy does,
+// e.g. to a cache entry, a response DTO and an outbox event at once.
+// dsts must be pointers of the same type, which is checked before copying anything.
+// src is walked only once to take a snapshot, which is copied to the destinations,
+// so accessors are called once and every destination gets the same values.
+// Each destination gets its own deep copy, so they never share references.
+// opts apply to all the copies as a single operation, e.g. sharing the budget and the time to stamp fields.
+// If a copy fails, the error tells the index of the destination,
+// and the destinations before it are already updated.
+func (p *Protector) CopyFanOut(tag string, src interface{}, dsts []interface{}, opts ...Option) error {
+	if src == nil {
+		return fmt.Errorf("src and dst must not be nil")
+	}
+	if len(dsts) == 0 {
+		return nil
+	}
+	for i, dst := range dsts {
+		dstVal := reflect.ValueOf(dst)
+		if dstVal.Kind() != reflect.Ptr || dstVal.IsNil() {
+			return fmt.Errorf("dst %d: %w, got %T", i, ErrDstNotPointer, dst)
+		}
+		if dstVal.Type() != reflect.TypeOf(dsts[0]) {
+			return fmt.Errorf("dst %d: dsts must be the same type, got %s and %s", i, reflect.TypeOf(dsts[0]), dstVal.Type())
+		}
+	}
+
+	srcVal, err := p.sourceValue(reflect.ValueOf(src), reflect.TypeOf(dsts[0]).Elem())
+	if err != nil {
+		return err
+	}
+	if srcVal.CanAddr() {
+		srcVal = srcVal.Addr()
+	}
+	snapshot, err := p.cloneWithState(p.newCopyState("", nil), srcVal.Interface())
+	if err != nil {
+		return err
+	}
+	snapshotVal := reflect.ValueOf(snapshot)
+	if snapshotVal.Kind() == reflect.Ptr && snapshotVal.Type() == reflect.TypeOf(dsts[0]) {
+		snapshotVal = snapshotVal.Elem()
+	}
+
+	s := p.newCopyState(tag, opts)
+	for i, dst := range dsts {
+		if err := p.copyRoot(s, snapshotVal, reflect.ValueOf(dst).Elem()); err != nil {
+			return fmt.Errorf("dst %d: %w", i, err)
+		}
 	}
+	return nil
+}
+
+// CloneWritable creates a deep copy of src with only fields writable for the tag.
+// See Protector.CloneWritable for details.
+func CloneWritable(tag string, src interface{}) interface{} {
+	return DefaultProtector.CloneWritable(tag, src)
+}
+
+// CloneWritable creates a deep copy of src with only fields writable for the tag.
+// Fields protected for the tag are left zero at any depth, including elements of slices and maps,
+// producing minimal DTOs for external systems that must never see server-managed fields.
+// The result has the same type as src, like Clone.
+func (p *Protector) CloneWritable(tag string, src interface{}) interface{} {
+	clone, _ := p.cloneWritable(tag, src)
+	return clone
+}
+
+// cloneWritable creates a deep copy of src with only fields writable for the tag,
+// or returns the error if src cannot be cloned.
+func (p *Protector) cloneWritable(tag string, src interface{}) (interface{}, error) {
+	s := p.newCopyState(tag, nil)
+	s.writableOnly = true
+	return p.cloneWithState(s, src)
+}
+
+// CloneFor creates a sanitized deep copy of src for the tag.
+// See Protector.CloneFor for details.
+func CloneFor(tag string, src interface{}) interface{} {
+	return DefaultProtector.CloneFor(tag, src)
+}
 
-	return p.copyValue(tag, srcVal, dstVal)
+// CloneFor creates a sanitized deep copy of src with fields protected for the tag left zero,
+// e.g. before sending entities to another subsystem.
+// This is the same as CloneWritable.
+func (p *Protector) CloneFor(tag string, src interface{}) interface{} {
+	return p.CloneWritable(tag, src)
 }
 
 // Clone creates a deep copy of src.
+// It returns nil if src cannot be cloned.
 func Clone(src interface{}) interface{} {
 	return DefaultProtector.Clone(src)
 }
 
 // Clone creates a deep copy of src.
+// Pointers back to ancestors are copied as pointers to their copies, so the clone has the same cycles.
+// Slice and map options of fields are ignored, as there is nothing to merge into.
+// It returns nil if src cannot be cloned; use CloneWithStats to get the error.
 func (p *Protector) Clone(src interface{}) interface{} {
+	clone, _ := p.cloneWithState(p.newCopyState("", nil), src)
+	return clone
+}
+
+// CloneWithStats creates a deep copy of src as Clone does, and returns the statistics of the clone.
+// See Protector.CloneWithStats for details.
+func CloneWithStats(src interface{}, opts ...Option) (interface{}, CloneStats, error) {
+	return DefaultProtector.CloneWithStats(src, opts...)
+}
+
+// CloneWithStats creates a deep copy of src as Clone does, and returns the statistics of the clone,
+// e.g. to measure how heavy cloning particular aggregates is for capacity planning of caches.
+// opts customize the clone; use WithBudget to abort excessively large clones with ErrBudgetExceeded.
+func (p *Protector) CloneWithStats(src interface{}, opts ...Option) (interface{}, CloneStats, error) {
+	s := p.newCopyState("", opts)
+	clone, err := p.cloneWithState(s, src)
+	if err != nil {
+		return nil, s.stats, err
+	}
+	return clone, s.stats, nil
+}
+
+// cloneWithState creates a deep copy of src in the operation.
+func (p *Protector) cloneWithState(s *copyState, src interface{}) (interface{}, error) {
+	s.cloning = true
 	if src == nil {
-		return nil
+		return nil, nil
 	}
 
 	srcVal := reflect.ValueOf(src)
@@ -126,31 +466,43 @@ func (p *Protector) Clone(src interface{}) interface{} {
 	// Handle pointer indirection
 	if srcVal.Kind() == reflect.Ptr {
 		if srcVal.IsNil() {
-			return nil
+			return nil, nil
 		}
 
 		// Create a new pointer of the same type
 		dstVal := reflect.New(srcVal.Elem().Type())
+		s.allocated()
+		// The root is an ancestor of all values in it
+		defer s.enterPointer(srcVal, dstVal)()
 		// Deep copy the pointed value
-		p.copyValue("", srcVal.Elem(), dstVal.Elem())
-		return dstVal.Interface()
+		if err := p.copyValue(s, srcVal.Elem(), dstVal.Elem()); err != nil {
+			return nil, err
+		}
+		return dstVal.Interface(), s.err
 	}
 
 	// For non-pointer values
 	dstVal := reflect.New(srcVal.Type())
-	p.copyValue("", srcVal, dstVal.Elem())
-	return dstVal.Elem().Interface()
+	s.allocated()
+	if err := p.copyValue(s, srcVal, dstVal.Elem()); err != nil {
+		return nil, err
+	}
+	return dstVal.Elem().Interface(), s.err
 }
 
 // copyValue copies a value from src to dst, respecting protection tags.
-func (p *Protector) copyValue(tag string, src, dst reflect.Value) error {
+func (p *Protector) copyValue(s *copyState, src, dst reflect.Value) error {
 	if !src.IsValid() || !dst.IsValid() {
 		return nil
 	}
+	if !s.visit() {
+		return s.err
+	}
 
 	// Check if it's a registered primitive struct type
-	if src.Kind() == reflect.Struct && p.IsPrimitiveStruct(src.Type()) {
+	if src.Kind() == reflect.Struct && s.isPrimitiveStruct(src.Type()) {
 		// For primitive structs, treat them like basic types and copy directly
+		s.debugf(s.path, "primitive struct %s, assigned", src.Type())
 		if dst.CanSet() {
 			dst.Set(src)
 		}
@@ -159,17 +511,24 @@ func (p *Protector) copyValue(tag string, src, dst reflect.Value) error {
 
 	switch src.Kind() {
 	case reflect.Struct:
-		return p.copyStruct(tag, src, dst)
+		return p.copyStruct(s, src, dst)
 	case reflect.Ptr:
-		return p.copyPtr(tag, src, dst)
+		return p.copyPtr(s, src, dst)
 	case reflect.Slice:
-		return p.copySlice(tag, src, dst)
+		return p.copySlice(s, src, dst)
+	case reflect.Array:
+		return p.copyArray(s, src, dst)
 	case reflect.Map:
-		return p.copyMap(tag, src, dst)
+		return p.copyMap(s, src, dst)
 	case reflect.Interface:
-		return p.copyInterface(tag, src, dst)
+		return p.copyInterface(s, src, dst)
+	case reflect.String:
+		if src.CanInterface() && dst.CanSet() {
+			dst.SetString(s.internString(src.String()))
+		}
+		return nil
 	default:
-		// For basic types (int, string, bool, etc.), just set the value
+		// For basic types (int, bool, etc.), just set the value
 		if src.CanInterface() && dst.CanSet() {
 			dst.Set(src)
 		}
@@ -178,9 +537,26 @@ func (p *Protector) copyValue(tag string, src, dst reflect.Value) error {
 }
 
 // copyStruct copies a struct from src to dst, respecting protection tags.
-func (p *Protector) copyStruct(tag string, src, dst reflect.Value) error {
+func (p *Protector) copyStruct(s *copyState, src, dst reflect.Value) error {
 	srcType := src.Type()
 
+	// Tags for promoted fields attached by the embedding struct
+	promoted := s.promoted
+	s.promoted = nil
+
+	// Rules and transitions are evaluated against dst before any field is copied
+	if len(s.rules) > 0 {
+		if err := p.checkRules(s, src, dst, promoted); err != nil {
+			return err
+		}
+	}
+	// Clones and copies without tags have no transitions to check
+	if p.hasTransitions.Load() && s.tag != "" && !s.cloning {
+		if err := p.checkTransitions(s, src, dst, promoted); err != nil {
+			return err
+		}
+	}
+
 	for i := 0; i < srcType.NumField(); i++ {
 		field := srcType.Field(i)
 
@@ -189,10 +565,22 @@ func (p *Protector) copyStruct(tag string, src, dst reflect.Value) error {
 			continue
 		}
 
+		// The version field is verified and bumped regardless of protection
+		if s.versionPath != "" && joinPath(s.path, field.Name) == s.versionPath {
+			s.debugf(s.versionPath, "version checked and bumped")
+			if err := bumpVersion(s.versionPath, src.Field(i), dst.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
 		// Check if the field should be protected
-		if tag != "" {
-			tagValue := field.Tag.Get(p.tagName)
-			if isProtected(tagValue, tag) {
+		if s.tag != "" {
+			tagValue := p.promotedProtectionTagValue(field, promoted)
+			if isProtected(tagValue, s.tag) {
+				if s.debug != nil {
+					s.debugf(joinPath(s.path, field.Name), "protected for %q, skipped", s.tag)
+				}
 				continue
 			}
 		}
@@ -204,33 +592,97 @@ func (p *Protector) copyStruct(tag string, src, dst reflect.Value) error {
 			continue
 		}
 
-		if err := p.copyValue(tag, srcField, dstField); err != nil {
+		// Guarded fields must not be changed
+		if len(s.guards) > 0 && !s.unguarded {
+			if fieldPath := joinPath(s.path, field.Name); s.guards[fieldPath] {
+				if srcField.IsZero() {
+					s.debugf(fieldPath, "guarded and zero in src, kept")
+					continue
+				}
+				if !reflect.DeepEqual(srcField.Interface(), dstField.Interface()) {
+					return &GuardError{Path: fieldPath, Src: srcField.Interface(), Dst: dstField.Interface()}
+				}
+			}
+		}
+
+		// Copy the reference as is for shallow fields
+		if hasOption(field.Tag.Get(p.optTagName), "shallow") {
+			if s.debug != nil {
+				s.debugf(joinPath(s.path, field.Name), "shallow, reference copied")
+			}
+			dstField.Set(srcField)
+			continue
+		}
+
+		if s.debug != nil {
+			s.debugf(joinPath(s.path, field.Name), "copying")
+		}
+		leave := s.enterField(field.Name, field.Tag.Get(p.optTagName))
+		if field.Anonymous {
+			s.promoted = promotedTagValues(field.Tag.Get(p.tagName))
+		}
+		err := p.copyValue(s, srcField, dstField)
+		s.promoted = nil
+		leave()
+		if err != nil {
 			return fmt.Errorf("error copying field %s: %w", field.Name, err)
 		}
 	}
 
+	p.stampStruct(s, dst)
 	return nil
 }
 
 // copyPtr copies a pointer from src to dst.
-func (p *Protector) copyPtr(tag string, src, dst reflect.Value) error {
+func (p *Protector) copyPtr(s *copyState, src, dst reflect.Value) error {
 	if src.IsNil() {
 		// If source is nil, set destination to nil as well
 		dst.Set(reflect.Zero(dst.Type()))
 		return nil
 	}
 
+	// Pointers back to ancestors form cycles
+	if copied, ok, err := s.ancestor(src, dst); err != nil {
+		return err
+	} else if ok {
+		dst.Set(copied)
+		return nil
+	}
+
 	// Create a new pointer if destination is nil
 	if dst.IsNil() {
 		dst.Set(reflect.New(dst.Type().Elem()))
+		s.allocated()
 	}
 
 	// Copy the underlying value
-	return p.copyValue(tag, src.Elem(), dst.Elem())
+	defer s.enterPointer(src, dst)()
+	return p.copyValue(s, src.Elem(), dst.Elem())
+}
+
+// copyArray copies an array from src to dst element by element.
+// Arrays are values, but their elements may hold pointers or protected fields,
+// so they cannot be copied by simple assignment.
+func (p *Protector) copyArray(s *copyState, src, dst reflect.Value) error {
+	// Arrays of plain values are copied at once
+	if s.isPlainType(src.Type().Elem()) {
+		s.debugf(s.path, "array of plain values, copied at once")
+		dst.Set(src)
+		return nil
+	}
+
+	defer s.enter("[]")()
+
+	for i := 0; i < src.Len(); i++ {
+		if err := p.copyValue(s, src.Index(i), dst.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // copyInterface copies an interface from src to dst.
-func (p *Protector) copyInterface(tag string, src, dst reflect.Value) error {
+func (p *Protector) copyInterface(s *copyState, src, dst reflect.Value) error {
 	if src.IsNil() {
 		dst.Set(reflect.Zero(dst.Type()))
 		return nil
@@ -243,7 +695,7 @@ func (p *Protector) copyInterface(tag string, src, dst reflect.Value) error {
 	dstElem := reflect.New(srcElem.Type()).Elem()
 
 	// Copy the value
-	if err := p.copyValue(tag, srcElem, dstElem); err != nil {
+	if err := p.copyValue(s, srcElem, dstElem); err != nil {
 		return err
 	}
 
@@ -252,39 +704,43 @@ func (p *Protector) copyInterface(tag string, src, dst reflect.Value) error {
 	return nil
 }
 
-// For testing purposes: set slice option
-func (p *Protector) setSliceOption(slice interface{}, option string) {
-	p.sliceOptions.Store(fmt.Sprintf("%p", slice), option)
-}
+// getSliceOption gets the slice operation option for the slice at the current path
+func (p *Protector) getSliceOption(s *copyState) string {
+	// Clones copy every element, as there is nothing to merge into
+	if s.cloning {
+		return "overwrite"
+	}
 
-// For testing purposes: set map option
-func (p *Protector) setMapOption(m interface{}, option string) {
-	mapVal := reflect.ValueOf(m)
-	if mapVal.Kind() == reflect.Map {
-		// マップの一意なIDとしてメモリアドレスを使用
-		p.mapOptions.Store(mapVal.UnsafePointer(), option)
+	if option, ok := s.sliceOptions[s.path]; ok {
+		return option
 	}
-}
 
-// getSliceOption gets the slice operation option from the options map or field tag
-func (p *Protector) getSliceOption(sliceVal reflect.Value) string {
-	// For testing: use override if available
-	key := fmt.Sprintf("%p", sliceVal.Interface())
-	if option, ok := p.sliceOptions.Load(key); ok {
-		return option.(string)
+	// Options declared with the protectopt tag of the field
+	if option, ok := s.fieldOption("overwrite", "match", "longer", "shorter", "set"); ok {
+		return option
+	}
+	if option, ok := s.fieldOptionWithValue("matchbykey"); ok {
+		return option
 	}
 
 	// Default option
 	return "overwrite"
 }
 
-// getMapOption gets the map operation option from the options map or field tag
-func (p *Protector) getMapOption(mapVal reflect.Value) string {
-	// For testing: use override if available
-	if mapVal.Kind() == reflect.Map {
-		if option, ok := p.mapOptions.Load(mapVal.UnsafePointer()); ok {
-			return option.(string)
-		}
+// getMapOption gets the map operation option for the map at the current path
+func (p *Protector) getMapOption(s *copyState) string {
+	// Clones copy every value, as there is nothing to merge into
+	if s.cloning {
+		return "overwrite"
+	}
+
+	if option, ok := s.mapOptions[s.path]; ok {
+		return option
+	}
+
+	// Options declared with the protectopt tag of the field
+	if option, ok := s.fieldOption("overwrite", "match", "patch", "sync"); ok {
+		return option
 	}
 
 	// Default option
@@ -292,16 +748,16 @@ func (p *Protector) getMapOption(mapVal reflect.Value) string {
 }
 
 // simpleCloneElement creates a simple clone of a value ignoring tags
-func (p *Protector) simpleCloneElement(src reflect.Value) reflect.Value {
+func (p *Protector) simpleCloneElement(s *copyState, src reflect.Value) reflect.Value {
 	// Simply clone the value without considering tags
-	if !src.IsValid() {
+	if !src.IsValid() || !s.visit() {
 		return reflect.Value{}
 	}
 
 	dst := reflect.New(src.Type()).Elem()
 
 	// Check if it's a registered primitive struct type
-	if src.Kind() == reflect.Struct && p.IsPrimitiveStruct(src.Type()) {
+	if src.Kind() == reflect.Struct && s.isPrimitiveStruct(src.Type()) {
 		// For primitive structs, treat them like basic types and copy directly
 		dst.Set(src)
 		return dst
@@ -309,6 +765,10 @@ func (p *Protector) simpleCloneElement(src reflect.Value) reflect.Value {
 
 	switch src.Kind() {
 	case reflect.Struct:
+		// Tags for promoted fields attached by the embedding struct
+		promoted := s.promoted
+		s.promoted = nil
+
 		srcType := src.Type()
 		for i := 0; i < srcType.NumField(); i++ {
 			field := srcType.Field(i)
@@ -316,22 +776,48 @@ func (p *Protector) simpleCloneElement(src reflect.Value) reflect.Value {
 				continue
 			}
 
+			// Protected fields are left zero in writable clones
+			if s.writableOnly && isProtected(p.promotedProtectionTagValue(field, promoted), s.tag) {
+				continue
+			}
+
 			srcField := src.Field(i)
 			dstField := dst.Field(i)
 
+			if dstField.CanSet() && hasOption(field.Tag.Get(p.optTagName), "shallow") {
+				dstField.Set(srcField)
+				continue
+			}
+
 			if dstField.CanSet() {
-				clonedVal := p.simpleCloneElement(srcField)
+				if field.Anonymous && s.writableOnly {
+					s.promoted = promotedTagValues(field.Tag.Get(p.tagName))
+				}
+				clonedVal := p.simpleCloneElement(s, srcField)
+				s.promoted = nil
 				if clonedVal.IsValid() {
 					dstField.Set(clonedVal)
 				}
 			}
 		}
+		p.stampStruct(s, dst)
 	case reflect.Ptr:
 		if src.IsNil() {
 			return dst // Zero value (nil pointer)
 		}
+		// Pointers back to ancestors form cycles
+		if copied, ok, err := s.ancestor(src, dst); err != nil {
+			s.err = err
+			return reflect.Value{}
+		} else if ok {
+			dst.Set(copied)
+			break
+		}
 		newPtr := reflect.New(src.Elem().Type())
-		clonedVal := p.simpleCloneElement(src.Elem())
+		s.allocated()
+		leave := s.enterPointer(src, newPtr)
+		clonedVal := p.simpleCloneElement(s, src.Elem())
+		leave()
 		if clonedVal.IsValid() {
 			newPtr.Elem().Set(clonedVal)
 		}
@@ -341,23 +827,41 @@ func (p *Protector) simpleCloneElement(src reflect.Value) reflect.Value {
 			return dst // Zero value (nil slice)
 		}
 		newSlice := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		s.allocated()
+		if s.isPlainType(src.Type().Elem()) {
+			reflect.Copy(newSlice, src)
+			dst.Set(newSlice)
+			break
+		}
 		for i := 0; i < src.Len(); i++ {
-			clonedVal := p.simpleCloneElement(src.Index(i))
+			clonedVal := p.simpleCloneElement(s, src.Index(i))
 			if clonedVal.IsValid() {
 				newSlice.Index(i).Set(clonedVal)
 			}
 		}
 		dst.Set(newSlice)
+	case reflect.Array:
+		if s.isPlainType(src.Type().Elem()) {
+			dst.Set(src)
+			break
+		}
+		for i := 0; i < src.Len(); i++ {
+			clonedVal := p.simpleCloneElement(s, src.Index(i))
+			if clonedVal.IsValid() {
+				dst.Index(i).Set(clonedVal)
+			}
+		}
 	case reflect.Map:
 		if src.IsNil() {
 			return dst // Zero value (nil map)
 		}
 		newMap := reflect.MakeMap(src.Type())
+		s.allocated()
 		iter := src.MapRange()
 		for iter.Next() {
 			k := iter.Key()
 			v := iter.Value()
-			clonedVal := p.simpleCloneElement(v)
+			clonedVal := p.simpleCloneElement(s, v)
 			if clonedVal.IsValid() {
 				newMap.SetMapIndex(k, clonedVal)
 			}
@@ -368,10 +872,12 @@ func (p *Protector) simpleCloneElement(src reflect.Value) reflect.Value {
 			return dst // Zero value (nil interface)
 		}
 		srcElem := src.Elem()
-		clonedVal := p.simpleCloneElement(srcElem)
+		clonedVal := p.simpleCloneElement(s, srcElem)
 		if clonedVal.IsValid() {
 			dst.Set(clonedVal)
 		}
+	case reflect.String:
+		dst.SetString(s.internString(src.String()))
 	default:
 		// For basic types, copy as is
 		dst.Set(src)
@@ -381,28 +887,43 @@ func (p *Protector) simpleCloneElement(src reflect.Value) reflect.Value {
 }
 
 // copySlice copies a slice from src to dst.
-func (p *Protector) copySlice(tag string, src, dst reflect.Value) error {
+func (p *Protector) copySlice(s *copyState, src, dst reflect.Value) error {
 	if src.IsNil() {
 		dst.Set(reflect.Zero(dst.Type()))
 		return nil
 	}
 
 	// Get the slice option
-	option := p.getSliceOption(dst)
+	option := p.getSliceOption(s)
+	s.debugf(s.path, "slice option %q (src %d, dst %d elements)", option, src.Len(), dst.Len())
+
+	// Elements are copied at the element path
+	defer s.enter("[]")()
 
 	srcLen := src.Len()
 	dstLen := dst.Len()
 
+	// Options may have a value like "matchbykey=ID"
+	option, value, _ := strings.Cut(option, "=")
+
 	switch option {
 	case "overwrite":
 		// Create a new slice with the same length as src
 		newSlice := reflect.MakeSlice(dst.Type(), srcLen, srcLen)
+		s.allocated()
+
+		// Slices of plain values are copied at once
+		if s.isPlainType(dst.Type().Elem()) {
+			reflect.Copy(newSlice, src)
+			dst.Set(newSlice)
+			break
+		}
 
 		// For overwrite option, simply clone each element ignoring tags
 		for i := 0; i < srcLen; i++ {
 			srcElem := src.Index(i)
 			dstElem := newSlice.Index(i)
-			clonedElem := p.simpleCloneElement(srcElem)
+			clonedElem := p.simpleCloneElement(s, srcElem)
 			if clonedElem.IsValid() {
 				dstElem.Set(clonedElem)
 			}
@@ -413,6 +934,7 @@ func (p *Protector) copySlice(tag string, src, dst reflect.Value) error {
 		// Adjust destination length to match source length
 		if dstLen != srcLen {
 			newSlice := reflect.MakeSlice(dst.Type(), srcLen, srcLen)
+			s.allocated()
 			// Copy existing elements if available
 			copyLen := dstLen
 			if copyLen > srcLen {
@@ -432,30 +954,15 @@ func (p *Protector) copySlice(tag string, src, dst reflect.Value) error {
 			// Use copyValue recursively to handle different element types properly
 			if i < dstLen {
 				// For existing elements in destination, apply normal protection rules
-				if err := p.copyValue(tag, srcElem, dstElem); err != nil {
+				s.debugf(s.path, "element %d merged into the existing element", i)
+				if err := p.copyValue(s, srcElem, dstElem); err != nil {
 					return err
 				}
 			} else {
 				// For new elements, create with protection
-				if srcElem.Kind() == reflect.Struct {
-					// For struct types, we need special handling to respect protection tags
-					structType := srcElem.Type()
-
-					// Create a new struct
-					newStructVal := reflect.New(structType).Elem()
-
-					// Apply copyStruct to copy fields with protection
-					if err := p.copyStruct(tag, srcElem, newStructVal); err != nil {
-						return err
-					}
-
-					// Set the new struct to the destination element
-					dstElem.Set(newStructVal)
-				} else {
-					// For non-struct types, use simple copy
-					if err := p.copyValue(tag, srcElem, dstElem); err != nil {
-						return err
-					}
+				s.debugf(s.path, "element %d added", i)
+				if err := p.copyNewElement(s, srcElem, dstElem); err != nil {
+					return err
 				}
 			}
 		}
@@ -464,6 +971,7 @@ func (p *Protector) copySlice(tag string, src, dst reflect.Value) error {
 		if dstLen < srcLen {
 			// Need to extend destination slice
 			newSlice := reflect.MakeSlice(dst.Type(), srcLen, srcLen)
+			s.allocated()
 			// Copy existing elements
 			for i := 0; i < dstLen; i++ {
 				newSlice.Index(i).Set(dst.Index(i))
@@ -483,7 +991,15 @@ func (p *Protector) copySlice(tag string, src, dst reflect.Value) error {
 			dstElem := dst.Index(i)
 
 			// Use copyValue to properly handle different types with protection rules
-			if err := p.copyValue(tag, srcElem, dstElem); err != nil {
+			if err := p.copyValue(s, srcElem, dstElem); err != nil {
+				return err
+			}
+		}
+
+		// Extended elements are created with protection
+		for i := copyLen; i < srcLen; i++ {
+			s.debugf(s.path, "element %d added", i)
+			if err := p.copyNewElement(s, src.Index(i), dst.Index(i)); err != nil {
 				return err
 			}
 		}
@@ -500,10 +1016,14 @@ func (p *Protector) copySlice(tag string, src, dst reflect.Value) error {
 			dstElem := dst.Index(i)
 
 			// Use copyValue to properly handle different types with protection rules
-			if err := p.copyValue(tag, srcElem, dstElem); err != nil {
+			if err := p.copyValue(s, srcElem, dstElem); err != nil {
 				return err
 			}
 		}
+	case "matchbykey":
+		return p.copySliceByKey(s, src, dst, value)
+	case "set":
+		return p.copySliceAsSet(s, src, dst)
 	default:
 		return fmt.Errorf("unknown slice option: %s", option)
 	}
@@ -511,20 +1031,163 @@ func (p *Protector) copySlice(tag string, src, dst reflect.Value) error {
 	return nil
 }
 
+// copySliceByKey copies a slice from src to dst pairing elements by the key field,
+// so that reordered or inserted elements are copied onto the existing elements with the same keys.
+// The result has the elements in the order of src; destination elements without paired source elements are removed.
+func (p *Protector) copySliceByKey(s *copyState, src, dst reflect.Value, key string) error {
+	keyOf := func(elem reflect.Value) (interface{}, bool, error) {
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				return nil, false, nil
+			}
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			return nil, false, fmt.Errorf("matchbykey requires struct elements, got %s", elem.Type())
+		}
+		field := elem.FieldByName(key)
+		if !field.IsValid() {
+			return nil, false, fmt.Errorf("matchbykey: %s has no field %s", elem.Type(), key)
+		}
+		if !field.Type().Comparable() || !isHashable(field) {
+			return nil, false, fmt.Errorf("matchbykey: field %s of %s is not comparable", key, elem.Type())
+		}
+		if field.IsZero() {
+			return nil, false, nil
+		}
+		return field.Interface(), true, nil
+	}
+
+	// Index destination elements by their keys; the first element wins for duplicate keys
+	existing := make(map[interface{}]int, dst.Len())
+	for i := 0; i < dst.Len(); i++ {
+		k, ok, err := keyOf(dst.Index(i))
+		if err != nil {
+			return err
+		}
+		if _, found := existing[k]; ok && !found {
+			existing[k] = i
+		}
+	}
+
+	newSlice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+	s.allocated()
+	used := make(map[int]bool, src.Len())
+	for i := 0; i < src.Len(); i++ {
+		srcElem := src.Index(i)
+		dstElem := newSlice.Index(i)
+
+		k, ok, err := keyOf(srcElem)
+		if err != nil {
+			return err
+		}
+		if j, found := existing[k]; ok && found && !used[j] {
+			// Copy onto the existing element with the same key
+			s.debugf(s.path, "element %d merged into the existing element %d with key %v", i, j, k)
+			used[j] = true
+			dstElem.Set(dst.Index(j))
+			if err := p.copyValue(s, srcElem, dstElem); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// New elements are created with protection
+		s.debugf(s.path, "element %d added", i)
+		if err := p.copyNewElement(s, srcElem, dstElem); err != nil {
+			return err
+		}
+	}
+
+	dst.Set(newSlice)
+	return nil
+}
+
+// copyNewElement copies src to the zero dst as a new element of a slice, respecting protection tags.
+// Guarded fields in new elements are not checked, as there are no existing values to compare with.
+func (p *Protector) copyNewElement(s *copyState, src, dst reflect.Value) error {
+	unguarded := s.unguarded
+	s.unguarded = true
+	defer func() {
+		s.unguarded = unguarded
+	}()
+	return p.copyValue(s, src, dst)
+}
+
+// copySliceAsSet merges src into dst as a set of comparable elements.
+// The result has the elements of dst followed by the elements only in src, without duplicates.
+func (p *Protector) copySliceAsSet(s *copyState, src, dst reflect.Value) error {
+	if !dst.Type().Elem().Comparable() {
+		return fmt.Errorf("set requires comparable elements, got %s", dst.Type().Elem())
+	}
+
+	seen := make(map[interface{}]bool, src.Len()+dst.Len())
+	newSlice := reflect.MakeSlice(dst.Type(), 0, src.Len()+dst.Len())
+	s.allocated()
+	for _, v := range []reflect.Value{dst, src} {
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			if !isHashable(elem) {
+				return fmt.Errorf("set requires comparable elements, got %s holding incomparable values", elem.Type())
+			}
+			if seen[elem.Interface()] {
+				continue
+			}
+			seen[elem.Interface()] = true
+			newSlice = reflect.Append(newSlice, elem)
+		}
+	}
+
+	s.debugf(s.path, "merged as a set of %d elements", newSlice.Len())
+	dst.Set(newSlice)
+	return nil
+}
+
+// isHashable checks if v of a comparable type can be used as a map key,
+// that is, no interface in it holds a value of an incomparable type such as a slice.
+func isHashable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+		return v.Elem().Type().Comparable() && isHashable(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !isHashable(v.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isHashable(v.Index(i)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // copyMap copies a map from src to dst.
-func (p *Protector) copyMap(tag string, src, dst reflect.Value) error {
+func (p *Protector) copyMap(s *copyState, src, dst reflect.Value) error {
 	if src.IsNil() {
 		dst.Set(reflect.Zero(dst.Type()))
 		return nil
 	}
 
 	// Get the map option
-	option := p.getMapOption(dst)
+	option := p.getMapOption(s)
+	s.debugf(s.path, "map option %q (src %d, dst %d keys)", option, src.Len(), dst.Len())
+	keep := s.keepKeys[s.path]
+
+	// Values are copied at the element path
+	defer s.enter("[]")()
 
 	switch option {
 	case "overwrite":
 		// Create a new map
 		newMap := reflect.MakeMap(dst.Type())
+		s.allocated()
 
 		// For overwrite option, simply clone each element ignoring tags
 		iter := src.MapRange()
@@ -533,7 +1196,7 @@ func (p *Protector) copyMap(tag string, src, dst reflect.Value) error {
 			v := iter.Value()
 
 			// Simple clone without considering tags
-			clonedVal := p.simpleCloneElement(v)
+			clonedVal := p.simpleCloneElement(s, v)
 			if clonedVal.IsValid() {
 				newMap.SetMapIndex(k, clonedVal)
 			}
@@ -543,6 +1206,7 @@ func (p *Protector) copyMap(tag string, src, dst reflect.Value) error {
 	case "match":
 		// Create a new map
 		newMap := reflect.MakeMap(dst.Type())
+		s.allocated()
 
 		// Copy values from source
 		iter := src.MapRange()
@@ -553,75 +1217,34 @@ func (p *Protector) copyMap(tag string, src, dst reflect.Value) error {
 			// Check if key exists in destination
 			dstV := dst.MapIndex(k)
 
-			if srcV.Kind() == reflect.Struct && dstV.IsValid() {
-				structType := srcV.Type()
-
-				// Create a temporary copy of srcV
-				tempVal := reflect.New(structType).Elem()
-
-				// First copy all fields from srcV to tempVal
-				for i := 0; i < structType.NumField(); i++ {
-					field := structType.Field(i)
-					if !field.IsExported() {
-						continue
-					}
+			// Existing values are copied with protection, and new values are cloned
+			newV := reflect.New(srcV.Type()).Elem()
 
-					srcField := srcV.Field(i)
-					tempField := tempVal.Field(i)
-
-					if tempField.CanSet() {
-						tempField.Set(srcField)
-					}
-				}
-
-				// Then copy all fields from dstV to tempVal for fields that should be protected
-				if tag != "" {
-					for i := 0; i < structType.NumField(); i++ {
-						field := structType.Field(i)
-						if !field.IsExported() {
-							continue
-						}
-
-						tagValue := field.Tag.Get(p.tagName)
-						if isProtected(tagValue, tag) {
-							dstField := dstV.Field(i)
-							tempField := tempVal.Field(i)
-
-							if tempField.CanSet() && dstField.IsValid() {
-								tempField.Set(dstField)
-							}
-						}
-					}
+			if dstV.IsValid() {
+				s.debugf(s.path, "key %v merged into the existing value", k)
+				// First set to existing value
+				newV.Set(p.existingMapValue(s, dstV))
+				// Then copy non-protected fields
+				if err := p.copyValue(s, srcV, newV); err != nil {
+					return err
 				}
-
-				// Set the value in the new map
-				newMap.SetMapIndex(k, tempVal)
 			} else {
-				// For new keys or non-struct values, create a new value
-				newV := reflect.New(srcV.Type()).Elem()
-
-				// Copy with tag protection for existing keys
-				if dstV.IsValid() {
-					// First set to existing value
-					newV.Set(dstV)
-					// Then copy non-protected fields
-					p.copyValue(tag, srcV, newV)
-				} else {
-					// For new keys, simple clone
-					newV = p.simpleCloneElement(srcV)
-				}
+				// For new keys, simple clone
+				s.debugf(s.path, "key %v cloned", k)
+				newV = p.simpleCloneElement(s, srcV)
+			}
 
-				if newV.IsValid() {
-					newMap.SetMapIndex(k, newV)
-				}
+			if newV.IsValid() {
+				newMap.SetMapIndex(k, newV)
 			}
 		}
 
 		dst.Set(newMap)
-	case "patch":
+	case "patch", "sync":
 		// Keep the existing map and add/update values from source
 		if dst.IsNil() {
 			dst.Set(reflect.MakeMap(dst.Type()))
+			s.allocated()
 		}
 
 		// Copy values from source, updating or adding as needed
@@ -634,50 +1257,34 @@ func (p *Protector) copyMap(tag string, src, dst reflect.Value) error {
 			dstV := dst.MapIndex(k)
 
 			if dstV.IsValid() {
-				// Key exists
-				if srcV.Kind() == reflect.Struct {
-					structType := srcV.Type()
-
-					// Create a temporary copy of dstV
-					tempVal := reflect.New(structType).Elem()
-					tempVal.Set(dstV)
-
-					// Copy fields from srcV to tempVal except protected fields
-					for i := 0; i < structType.NumField(); i++ {
-						field := structType.Field(i)
-						if !field.IsExported() {
-							continue
-						}
-
-						tagValue := field.Tag.Get(p.tagName)
-						if !isProtected(tagValue, tag) || tag == "" {
-							// Copy this field from src to dst
-							srcField := srcV.Field(i)
-							tempField := tempVal.Field(i)
-
-							if tempField.CanSet() {
-								tempField.Set(srcField)
-							}
-						}
-					}
-
-					// Update the map with the modified value
-					dst.SetMapIndex(k, tempVal)
-				} else {
-					// For non-struct type, use copyValue with tag protection
-					tempV := reflect.New(srcV.Type()).Elem()
-					tempV.Set(dstV)
-					p.copyValue(tag, srcV, tempV)
-					dst.SetMapIndex(k, tempV)
+				// Key exists - copy with tag protection onto the existing value
+				s.debugf(s.path, "key %v merged into the existing value", k)
+				tempV := reflect.New(srcV.Type()).Elem()
+				tempV.Set(p.existingMapValue(s, dstV))
+				if err := p.copyValue(s, srcV, tempV); err != nil {
+					return err
 				}
+				dst.SetMapIndex(k, tempV)
 			} else {
 				// Key doesn't exist - simple clone
-				clonedVal := p.simpleCloneElement(srcV)
+				s.debugf(s.path, "key %v cloned", k)
+				clonedVal := p.simpleCloneElement(s, srcV)
 				if clonedVal.IsValid() {
 					dst.SetMapIndex(k, clonedVal)
 				}
 			}
 		}
+
+		if option == "sync" {
+			// Delete keys absent from source unless they are kept
+			for _, k := range dst.MapKeys() {
+				if src.MapIndex(k).IsValid() || containsValue(keep, k) {
+					continue
+				}
+				s.debugf(s.path, "key %v deleted", k)
+				dst.SetMapIndex(k, reflect.Value{})
+			}
+		}
 	default:
 		return fmt.Errorf("unknown map option: %s", option)
 	}
@@ -685,21 +1292,128 @@ func (p *Protector) copyMap(tag string, src, dst reflect.Value) error {
 	return nil
 }
 
+// existingMapValue returns the value to merge a source map value into for an existing key.
+// Pointer values are merged into the pointed value in place, so that other references see the update,
+// unless WithMapPointerReplace is specified.
+func (p *Protector) existingMapValue(s *copyState, dstV reflect.Value) reflect.Value {
+	if s.replaceMapPointers && dstV.Kind() == reflect.Ptr && !dstV.IsNil() {
+		return p.simpleCloneElement(s, dstV)
+	}
+	return dstV
+}
+
+// ProtectedFields returns the paths of fields protected for the tag in the type of v.
+// See Protector.ProtectedFields for details.
+func ProtectedFields(tag string, v interface{}) []string {
+	return DefaultProtector.ProtectedFields(tag, v)
+}
+
+// ProtectedFields returns the paths of fields protected for the tag in the type of v.
+// Nested fields are separated by dots, and elements of slices, arrays and maps are denoted by "[]"
+// (e.g. "Items[].ID").
+// Fields in protected fields and in primitive structs are not listed,
+// and fields of recursive types are listed only at their first occurrence.
+func (p *Protector) ProtectedFields(tag string, v interface{}) []string {
+	if v == nil {
+		return nil
+	}
+
+	var fields []string
+	p.collectProtectedFields(tag, reflect.TypeOf(v), "", nil, map[reflect.Type]bool{}, &fields)
+	return fields
+}
+
+// collectProtectedFields collects paths of protected fields in t into fields.
+// visiting holds types being walked to stop at recursive types.
+func (p *Protector) collectProtectedFields(tag string, t reflect.Type, path string, promoted map[string]string, visiting map[reflect.Type]bool, fields *[]string) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		p.collectProtectedFields(tag, t.Elem(), path, promoted, visiting, fields)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		p.collectProtectedFields(tag, t.Elem(), joinPath(path, "[]"), nil, visiting, fields)
+	case reflect.Struct:
+		if p.IsPrimitiveStruct(t) || visiting[t] {
+			return
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldPath := joinPath(path, field.Name)
+
+			if isProtected(p.promotedProtectionTagValue(field, promoted), tag) {
+				*fields = append(*fields, fieldPath)
+				continue
+			}
+
+			var fieldPromoted map[string]string
+			if field.Anonymous {
+				fieldPromoted = promotedTagValues(field.Tag.Get(p.tagName))
+			}
+			p.collectProtectedFields(tag, field.Type, fieldPath, fieldPromoted, visiting, fields)
+		}
+	}
+}
+
+// wildcardTag is the tag value to protect fields for every tag, e.g. `protectfor:"*"`.
+const wildcardTag = "*"
+
+// negationPrefix is the prefix of tag values to protect fields for every tag except the tag,
+// e.g. `protectfor:"!create"`.
+const negationPrefix = "!"
+
 // isProtected checks if the field with the given tag value should be protected for the specified tag.
+// The wildcard tag "*" matches every tag,
+// and negated tags like "!create" protect the field for every tag except the negated ones.
+// Groups of tags joined with joinTagGroups are evaluated independently,
+// and the field is protected if any of them protects it.
 func isProtected(tagValue, tag string) bool {
 	if tagValue == "" || tag == "" {
 		return false
 	}
 
+	for _, group := range strings.Split(tagValue, tagGroupSeparator) {
+		if isProtectedByGroup(group, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// isProtectedByGroup checks if the comma-separated tags protect the field for the tag.
+func isProtectedByGroup(tagValue, tag string) bool {
 	// Split comma-separated values
 	tags := strings.Split(tagValue, ",")
 
+	hasNegation, negated := false, false
 	for _, t := range tags {
-		if strings.TrimSpace(t) == tag {
+		t = strings.TrimSpace(t)
+		switch {
+		case t == tag || t == wildcardTag:
 			return true
+		case strings.HasPrefix(t, negationPrefix):
+			hasNegation = true
+			if t[len(negationPrefix):] == tag {
+				negated = true
+			}
 		}
 	}
 
+	return hasNegation && !negated
+}
+
+// hasOption checks if the comma-separated option tag value contains the option.
+func hasOption(optValue, option string) bool {
+	for _, o := range strings.Split(optValue, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
 	return false
 }
 
@@ -711,8 +1425,10 @@ func isProtected(tagValue, tag string) bool {
 // - "match": Adjusts destination length to match source length
 // - "longer": Keeps destination if longer than source, otherwise extends it
 // - "shorter": Truncates to the shorter of the two slices
-func CopySlice(tag string, src, dst interface{}, option string) error {
-	return DefaultProtector.CopySlice(tag, src, dst, option)
+// - "matchbykey=<field>": Pairs struct elements by the key field (e.g. "matchbykey=ID") instead of indices
+// - "set": Merges comparable elements (e.g. []string) as a set, keeping the order and dropping duplicates
+func CopySlice(tag string, src, dst interface{}, option string, opts ...Option) error {
+	return DefaultProtector.CopySlice(tag, src, dst, option, opts...)
 }
 
 // CopySlice copies values from src to dst slice with the specified option.
@@ -723,7 +1439,9 @@ func CopySlice(tag string, src, dst interface{}, option string) error {
 // - "match": Adjusts destination length to match source length
 // - "longer": Keeps destination if longer than source, otherwise extends it
 // - "shorter": Truncates to the shorter of the two slices
-func (p *Protector) CopySlice(tag string, src, dst interface{}, option string) error {
+// - "matchbykey=<field>": Pairs struct elements by the key field (e.g. "matchbykey=ID") instead of indices
+// - "set": Merges comparable elements (e.g. []string) as a set, keeping the order and dropping duplicates
+func (p *Protector) CopySlice(tag string, src, dst interface{}, option string, opts ...Option) error {
 	if src == nil || dst == nil {
 		return fmt.Errorf("src and dst must not be nil")
 	}
@@ -740,7 +1458,7 @@ func (p *Protector) CopySlice(tag string, src, dst interface{}, option string) e
 	}
 
 	if dstVal.Kind() != reflect.Ptr {
-		return fmt.Errorf("dst must be a pointer")
+		return fmt.Errorf("%w, got %s: pass the address of the destination (e.g. &dst)", ErrDstNotPointer, dstVal.Type())
 	}
 
 	if dstVal.IsNil() {
@@ -750,7 +1468,13 @@ func (p *Protector) CopySlice(tag string, src, dst interface{}, option string) e
 	dstVal = dstVal.Elem()
 
 	if srcVal.Type() != dstVal.Type() {
-		return fmt.Errorf("src and dst must be the same type, got %s and %s", srcVal.Type(), dstVal.Type())
+		if dstVal.Kind() == reflect.Interface && srcVal.Kind() != reflect.Interface {
+			return fmt.Errorf("%w, got pointer to %s: did you mean to pass the pointer held in the interface instead of its address?", ErrDstNotPointer, dstVal.Type())
+		}
+		if !p.isAssignConvertible(srcVal.Type(), dstVal.Type()) {
+			return fmt.Errorf("src and dst must be the same type, got %s and %s", srcVal.Type(), dstVal.Type())
+		}
+		srcVal = srcVal.Convert(dstVal.Type())
 	}
 
 	// Ensure both src and dst are slices
@@ -758,11 +1482,57 @@ func (p *Protector) CopySlice(tag string, src, dst interface{}, option string) e
 		return fmt.Errorf("src and dst must be slices, got %s and %s", srcVal.Kind(), dstVal.Kind())
 	}
 
-	// Override slice option for this operation
-	// Save the original option in a temporary variable
-	p.sliceOptions.Store(fmt.Sprintf("%p", dstVal.Interface()), option)
-	defer p.sliceOptions.Delete(fmt.Sprintf("%p", dstVal.Interface()))
+	// Pass the slice option for the root slice through the state of this operation
+	s := p.newCopyState(tag, opts)
+	s.setSliceOption("", option)
+	if err := s.checkVersionPath(dstVal.Type()); err != nil {
+		return err
+	}
 
 	// Use the existing copySlice function with the specified option
-	return p.copySlice(tag, srcVal, dstVal)
+	if err := p.copySlice(s, srcVal, dstVal); err != nil {
+		return err
+	}
+	if s.err != nil {
+		return s.err
+	}
+	return p.initialize(s, dstVal)
+}
+
+// CopyMap copies values from src to dst map with the specified option.
+// It handles map copying with more control than the regular Copy function, mirroring CopySlice.
+// The tag value is used to protect fields in map values.
+// The option parameter controls how maps are copied and can be one of:
+// - "overwrite": Creates a new map and copies all values (default)
+// - "match": Copies onto values of common keys and removes keys only in destination
+// - "patch": Copies onto values of common keys and keeps keys only in destination
+// - "sync": Same as "patch" but deletes keys only in destination except those kept with WithKeepKeys
+func CopyMap(tag string, src, dst interface{}, option string, opts ...Option) error {
+	return DefaultProtector.CopyMap(tag, src, dst, option, opts...)
+}
+
+// CopyMap copies values from src to dst map with the specified option.
+// It handles map copying with more control than the regular Copy function, mirroring CopySlice.
+// The tag value is used to protect fields in map values.
+// The option parameter controls how maps are copied and can be one of:
+// - "overwrite": Creates a new map and copies all values (default)
+// - "match": Copies onto values of common keys and removes keys only in destination
+// - "patch": Copies onto values of common keys and keeps keys only in destination
+// - "sync": Same as "patch" but deletes keys only in destination except those kept with WithKeepKeys
+func (p *Protector) CopyMap(tag string, src, dst interface{}, option string, opts ...Option) error {
+	// Ensure both src and dst are maps
+	srcType := reflect.TypeOf(src)
+	if srcType != nil && srcType.Kind() == reflect.Ptr {
+		srcType = srcType.Elem()
+	}
+	dstType := reflect.TypeOf(dst)
+	if dstType != nil && dstType.Kind() == reflect.Ptr {
+		dstType = dstType.Elem()
+	}
+	if srcType != nil && dstType != nil && (srcType.Kind() != reflect.Map || dstType.Kind() != reflect.Map) {
+		return fmt.Errorf("src and dst must be maps, got %s and %s", srcType.Kind(), dstType.Kind())
+	}
+
+	// The option for the root map takes precedence over options in opts
+	return p.Copy(tag, src, dst, append(opts[:len(opts):len(opts)], WithMapOption("", option))...)
 }
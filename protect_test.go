@@ -1,6 +1,7 @@
 package protect
 
 import (
+	"reflect"
 	"testing"
 	"time"
 
@@ -271,11 +272,8 @@ func TestSliceOptions(t *testing.T) {
 			},
 		}
 
-		// スライスに対するオプションをオーバーライドして設定
-		p.setSliceOption(dst.Items, "match")
-
-		// このテストでは明示的に create タグを指定
-		err := p.Copy("create", &src, &dst)
+		// このテストでは明示的に create タグを指定し、スライスに対するオプションを設定
+		err := p.Copy("create", &src, &dst, WithSliceOption("Items", "match"))
 		assert.NoError(t, err)
 
 		// Length should match the source length (2 items)
@@ -307,11 +305,8 @@ func TestSliceOptions(t *testing.T) {
 
 		originalLength := len(dst.LongList)
 
-		// スライスに対するオプションをオーバーライドして設定
-		p.setSliceOption(dst.LongList, "longer")
-
-		// このテストでは明示的に create タグを指定
-		err := p.Copy("create", &src, &dst)
+		// このテストでは明示的に create タグを指定し、スライスに対するオプションを設定
+		err := p.Copy("create", &src, &dst, WithSliceOption("LongList", "longer"))
 		assert.NoError(t, err)
 
 		// Length should not be changed since destination is longer
@@ -351,11 +346,8 @@ func TestSliceOptions(t *testing.T) {
 
 		originalLength := len(dst.ShortList)
 
-		// スライスに対するオプションをオーバーライドして設定
-		p.setSliceOption(dst.ShortList, "shorter")
-
-		// このテストでは明示的に create タグを指定
-		err := p.Copy("create", &src, &dst)
+		// このテストでは明示的に create タグを指定し、スライスに対するオプションを設定
+		err := p.Copy("create", &src, &dst, WithSliceOption("ShortList", "shorter"))
 		assert.NoError(t, err)
 
 		// Only copy as many items as the destination has
@@ -423,11 +415,8 @@ func TestMapOptions(t *testing.T) {
 			},
 		}
 
-		// マップに対するオプションをオーバーライドして設定
-		p.setMapOption(dst.MapItems, "patch")
-
-		// このテストでは明示的に create タグを指定
-		err := p.Copy("create", &src, &dst)
+		// このテストでは明示的に create タグを指定し、マップに対するオプションを設定
+		err := p.Copy("create", &src, &dst, WithMapOption("MapItems", "patch"))
 		assert.NoError(t, err)
 
 		// Should patch the map (add or update, but not remove)
@@ -460,11 +449,8 @@ func TestMapOptions(t *testing.T) {
 			},
 		}
 
-		// マップに対するオプションをオーバーライドして設定
-		p.setMapOption(dst.MapMatch, "match")
-
-		// このテストでは明示的に create タグを指定
-		err := p.Copy("create", &src, &dst)
+		// このテストでは明示的に create タグを指定し、マップに対するオプションを設定
+		err := p.Copy("create", &src, &dst, WithMapOption("MapMatch", "match"))
 		assert.NoError(t, err)
 
 		// Should make the destination match the source (same keys)
@@ -562,6 +548,23 @@ func TestCopySlice(t *testing.T) {
 		assert.Equal(t, "Extra", dst[2].ID)
 	})
 
+	t.Run("longer option extending destination", func(t *testing.T) {
+		src := []SimpleStruct{
+			{ID: "1", Code: "A", Name: "First"},
+			{ID: "2", Code: "B", Name: "Second"},
+		}
+		dst := []SimpleStruct{{ID: "X", Code: "Y", Name: "Existing"}}
+
+		err := CopySlice("update", &src, &dst, "longer")
+		assert.NoError(t, err)
+
+		// Appended elements never get protected fields from src
+		assert.Equal(t, []SimpleStruct{
+			{ID: "X", Code: "Y", Name: "First"},
+			{Name: "Second"},
+		}, dst)
+	})
+
 	t.Run("explicit shorter option", func(t *testing.T) {
 		src := []SimpleStruct{
 			{ID: "1", Code: "A", Name: "First"},
@@ -712,3 +715,1331 @@ func TestCopySlice(t *testing.T) {
 		assert.Equal(t, src[1].Parent.Name, dst[1].Parent.Name)
 	})
 }
+
+func TestCopyMap(t *testing.T) {
+	src := map[string]SimpleStruct{"a": {ID: "N1", Name: "New"}}
+
+	t.Run("overwrite", func(t *testing.T) {
+		dst := map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}}
+		err := CopyMap("create", &src, &dst, "overwrite")
+		assert.NoError(t, err)
+		assert.Equal(t, src, dst)
+	})
+
+	t.Run("match", func(t *testing.T) {
+		dst := map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}}
+		err := CopyMap("create", &src, &dst, "match")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]SimpleStruct{"a": {ID: "X1", Name: "New"}}, dst)
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		dst := map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}}
+		err := CopyMap("create", &src, &dst, "patch")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]SimpleStruct{"a": {ID: "X1", Name: "New"}, "b": {ID: "X2", Name: "Old"}}, dst)
+	})
+
+	t.Run("option takes precedence over options for the root", func(t *testing.T) {
+		dst := map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}}
+		err := CopyMap("create", &src, &dst, "patch", WithMapOption("", "overwrite"))
+		assert.NoError(t, err)
+		assert.Len(t, dst, 2)
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		dst := map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}}
+		err := CopyMap("create", &src, &dst, "unknown")
+		assert.Error(t, err)
+	})
+
+	t.Run("not maps", func(t *testing.T) {
+		dst := []SimpleStruct{}
+		err := CopyMap("create", &[]SimpleStruct{}, &dst, "patch")
+		assert.ErrorContains(t, err, "must be maps")
+	})
+}
+
+type NestedPointerStruct struct {
+	ID          string `protectfor:"create,update"`
+	PtrPtr      **SimpleStruct
+	PtrSlice    *[]SimpleStruct
+	SlicePtrs   []*[]SimpleStruct
+	PtrArray    *[2]SimpleStruct
+	ArrayPtrs   [2]*SimpleStruct
+	StructArray [2]SimpleStruct
+}
+
+func TestNestedPointers(t *testing.T) {
+	t.Run("clone does not share storage", func(t *testing.T) {
+		ptr := &SimpleStruct{ID: "pp", Code: "PP", Name: "PtrPtr"}
+		slice := []SimpleStruct{{ID: "ps", Code: "PS", Name: "PtrSlice"}}
+		src := NestedPointerStruct{
+			ID:          "123",
+			PtrPtr:      &ptr,
+			PtrSlice:    &slice,
+			SlicePtrs:   []*[]SimpleStruct{&slice},
+			PtrArray:    &[2]SimpleStruct{{ID: "pa", Code: "PA", Name: "PtrArray"}},
+			ArrayPtrs:   [2]*SimpleStruct{ptr, nil},
+			StructArray: [2]SimpleStruct{{ID: "sa", Code: "SA", Name: "StructArray"}},
+		}
+
+		cloned := Clone(&src).(*NestedPointerStruct)
+
+		assert.NotSame(t, src.PtrPtr, cloned.PtrPtr)
+		assert.NotSame(t, *src.PtrPtr, *cloned.PtrPtr)
+		assert.Equal(t, **src.PtrPtr, **cloned.PtrPtr)
+
+		assert.NotSame(t, src.PtrSlice, cloned.PtrSlice)
+		assert.Equal(t, *src.PtrSlice, *cloned.PtrSlice)
+
+		assert.NotSame(t, src.SlicePtrs[0], cloned.SlicePtrs[0])
+		assert.Equal(t, *src.SlicePtrs[0], *cloned.SlicePtrs[0])
+
+		assert.NotSame(t, src.PtrArray, cloned.PtrArray)
+		assert.Equal(t, *src.PtrArray, *cloned.PtrArray)
+
+		assert.NotSame(t, src.ArrayPtrs[0], cloned.ArrayPtrs[0])
+		assert.Equal(t, *src.ArrayPtrs[0], *cloned.ArrayPtrs[0])
+		assert.Nil(t, cloned.ArrayPtrs[1])
+
+		assert.Equal(t, src.StructArray, cloned.StructArray)
+
+		// Modifying the source doesn't affect the clone
+		(*src.PtrPtr).Name = "Modified"
+		(*src.PtrSlice)[0].Name = "Modified"
+		src.PtrArray[0].Name = "Modified"
+		assert.Equal(t, "PtrPtr", (*cloned.PtrPtr).Name)
+		assert.Equal(t, "PtrSlice", (*cloned.PtrSlice)[0].Name)
+		assert.Equal(t, "PtrSlice", (*cloned.SlicePtrs[0])[0].Name)
+		assert.Equal(t, "PtrArray", cloned.PtrArray[0].Name)
+		assert.Equal(t, "PtrPtr", cloned.ArrayPtrs[0].Name)
+	})
+
+	t.Run("copy applies protection inside arrays", func(t *testing.T) {
+		ptr := &SimpleStruct{ID: "pp", Code: "PP", Name: "PtrPtr"}
+		src := NestedPointerStruct{
+			ID:          "123",
+			PtrPtr:      &ptr,
+			PtrArray:    &[2]SimpleStruct{{ID: "pa", Code: "PA", Name: "PtrArray"}},
+			ArrayPtrs:   [2]*SimpleStruct{ptr, nil},
+			StructArray: [2]SimpleStruct{{ID: "sa", Code: "SA", Name: "StructArray"}},
+		}
+		dst := NestedPointerStruct{
+			PtrArray:    &[2]SimpleStruct{{ID: "X", Code: "Y", Name: "Existing"}},
+			ArrayPtrs:   [2]*SimpleStruct{{ID: "X", Code: "Y", Name: "Existing"}, nil},
+			StructArray: [2]SimpleStruct{{ID: "X", Code: "Y", Name: "Existing"}},
+		}
+
+		err := Copy("update", &src, &dst)
+		assert.NoError(t, err)
+
+		assert.Empty(t, dst.ID)
+
+		assert.Equal(t, "X", dst.PtrArray[0].ID) // ID is protected
+		assert.Equal(t, "Y", dst.PtrArray[0].Code)
+		assert.Equal(t, "PtrArray", dst.PtrArray[0].Name)
+
+		assert.NotSame(t, src.ArrayPtrs[0], dst.ArrayPtrs[0])
+		assert.Equal(t, "X", dst.ArrayPtrs[0].ID) // ID is protected
+		assert.Equal(t, "PtrPtr", dst.ArrayPtrs[0].Name)
+
+		assert.Equal(t, "X", dst.StructArray[0].ID) // ID is protected
+		assert.Equal(t, "StructArray", dst.StructArray[0].Name)
+
+		assert.NotSame(t, *src.PtrPtr, *dst.PtrPtr)
+		assert.Empty(t, (*dst.PtrPtr).ID) // ID is protected
+		assert.Equal(t, "PtrPtr", (*dst.PtrPtr).Name)
+	})
+}
+
+type UserID string
+
+type SimpleSlice []SimpleStruct
+
+type AnotherSimpleStruct struct {
+	ID   string
+	Code string
+	Name string
+}
+
+func TestAssignConvertible(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		p := createTestProtector()
+
+		src := UserID("user-1")
+		var dst string
+
+		err := p.Copy("update", &src, &dst)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must be the same type")
+	})
+
+	t.Run("named string type", func(t *testing.T) {
+		p := createTestProtector()
+		p.SetAssignConvertible(true)
+
+		src := UserID("user-1")
+		var dst string
+
+		err := p.Copy("update", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, "user-1", dst)
+	})
+
+	t.Run("named slice type", func(t *testing.T) {
+		p := createTestProtector()
+		p.SetAssignConvertible(true)
+
+		src := []SimpleStruct{{ID: "1", Code: "A", Name: "First"}}
+		dst := SimpleSlice{{ID: "X", Code: "Y", Name: "Existing"}}
+
+		err := p.CopySlice("update", &src, &dst, "match")
+		assert.NoError(t, err)
+		assert.Equal(t, "X", dst[0].ID) // ID is protected
+		assert.Equal(t, "Y", dst[0].Code)
+		assert.Equal(t, "First", dst[0].Name)
+	})
+
+	t.Run("tags of dst are used", func(t *testing.T) {
+		p := createTestProtector()
+		p.SetAssignConvertible(true)
+
+		src := AnotherSimpleStruct{ID: "1", Code: "A", Name: "First"}
+		dst := SimpleStruct{ID: "X", Code: "Y", Name: "Existing"}
+
+		err := p.Copy("update", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, "X", dst.ID)
+		assert.Equal(t, "Y", dst.Code)
+		assert.Equal(t, "First", dst.Name)
+	})
+
+	t.Run("different kinds", func(t *testing.T) {
+		p := createTestProtector()
+		p.SetAssignConvertible(true)
+
+		src := 65
+		var dst string
+
+		err := p.Copy("update", &src, &dst)
+		assert.Error(t, err)
+	})
+}
+
+func TestDstNotPointer(t *testing.T) {
+	t.Run("dst passed by value", func(t *testing.T) {
+		src := SimpleStruct{ID: "1", Code: "A", Name: "Test"}
+		dst := SimpleStruct{}
+
+		err := Copy("create", &src, dst)
+		assert.ErrorIs(t, err, ErrDstNotPointer)
+		assert.Contains(t, err.Error(), "&dst")
+	})
+
+	t.Run("dst passed as pointer to interface", func(t *testing.T) {
+		src := SimpleStruct{ID: "1", Code: "A", Name: "Test"}
+		var dst interface{} = &SimpleStruct{}
+
+		err := Copy("create", &src, &dst)
+		assert.ErrorIs(t, err, ErrDstNotPointer)
+		assert.Contains(t, err.Error(), "did you mean")
+	})
+
+	t.Run("slice passed by value", func(t *testing.T) {
+		src := []SimpleStruct{{ID: "1", Code: "A", Name: "Test"}}
+		dst := []SimpleStruct{}
+
+		err := CopySlice("create", &src, dst, "match")
+		assert.ErrorIs(t, err, ErrDstNotPointer)
+	})
+
+	t.Run("interface to interface", func(t *testing.T) {
+		var src interface{} = SimpleStruct{ID: "1", Code: "A", Name: "Test"}
+		var dst interface{}
+
+		err := Copy("create", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, "A", dst.(SimpleStruct).Code)
+	})
+}
+
+type RecursiveStruct struct {
+	ID       string `protectfor:"update"`
+	Children []*RecursiveStruct
+}
+
+func TestProtectedFields(t *testing.T) {
+	t.Run("simple struct", func(t *testing.T) {
+		assert.Equal(t, []string{"ID"}, ProtectedFields("create", SimpleStruct{}))
+		assert.Equal(t, []string{"ID", "Code"}, ProtectedFields("update", &SimpleStruct{}))
+		assert.Empty(t, ProtectedFields("", SimpleStruct{}))
+	})
+
+	t.Run("nested struct", func(t *testing.T) {
+		assert.Equal(t, []string{"ID", "Parent.ID", "Child.ID"}, ProtectedFields("create", NestedStruct{}))
+	})
+
+	t.Run("collections", func(t *testing.T) {
+		assert.Equal(t, []string{
+			"ID",
+			"Items[].ID",
+			"LongList[].ID",
+			"ShortList[].ID",
+			"MapItems[].ID",
+			"MapMatch[].ID",
+		}, ProtectedFields("create", SliceWithOptions{}))
+	})
+
+	t.Run("recursive struct", func(t *testing.T) {
+		assert.Equal(t, []string{"ID"}, ProtectedFields("update", RecursiveStruct{}))
+	})
+}
+
+type LookupTable struct {
+	Entries map[string]string
+}
+
+type ShallowStruct struct {
+	ID      string         `protectfor:"create,update"`
+	Table   *LookupTable   `protectopt:"shallow"`
+	Blob    []byte         `protectopt:"shallow"`
+	Items   []SimpleStruct `protectopt:"shallow"`
+	Deep    *LookupTable
+	Wrapped []ShallowStruct
+}
+
+func TestShallowOption(t *testing.T) {
+	t.Run("copy", func(t *testing.T) {
+		src := ShallowStruct{
+			ID:    "123",
+			Table: &LookupTable{Entries: map[string]string{"a": "A"}},
+			Blob:  []byte("blob"),
+			Items: []SimpleStruct{{ID: "1", Code: "A", Name: "First"}},
+			Deep:  &LookupTable{Entries: map[string]string{"b": "B"}},
+		}
+		dst := ShallowStruct{}
+
+		err := Copy("update", &src, &dst)
+		assert.NoError(t, err)
+
+		assert.Empty(t, dst.ID)
+		assert.Same(t, src.Table, dst.Table)
+		assert.Same(t, &src.Blob[0], &dst.Blob[0])
+		assert.Same(t, &src.Items[0], &dst.Items[0])
+		assert.NotSame(t, src.Deep, dst.Deep)
+		assert.Equal(t, src.Deep, dst.Deep)
+	})
+
+	t.Run("clone", func(t *testing.T) {
+		src := ShallowStruct{
+			Table: &LookupTable{Entries: map[string]string{"a": "A"}},
+			Deep:  &LookupTable{Entries: map[string]string{"b": "B"}},
+			Wrapped: []ShallowStruct{{
+				Table: &LookupTable{Entries: map[string]string{"c": "C"}},
+				Deep:  &LookupTable{Entries: map[string]string{"d": "D"}},
+			}},
+		}
+
+		cloned := Clone(&src).(*ShallowStruct)
+
+		assert.Same(t, src.Table, cloned.Table)
+		assert.NotSame(t, src.Deep, cloned.Deep)
+
+		// Shallow fields are shared also in elements cloned in overwrite mode
+		assert.Same(t, src.Wrapped[0].Table, cloned.Wrapped[0].Table)
+		assert.NotSame(t, src.Wrapped[0].Deep, cloned.Wrapped[0].Deep)
+	})
+}
+
+type PointerMapStruct struct {
+	Items map[string]*SimpleStruct
+}
+
+func TestPointerMapValues(t *testing.T) {
+	for _, option := range []string{"patch", "match"} {
+		t.Run(option+" merges in place", func(t *testing.T) {
+			src := PointerMapStruct{
+				Items: map[string]*SimpleStruct{
+					"first":  {ID: "1", Code: "A", Name: "First"},
+					"second": {ID: "2", Code: "B", Name: "Second"},
+				},
+			}
+			existing := &SimpleStruct{ID: "X", Code: "Y", Name: "Existing"}
+			dst := PointerMapStruct{
+				Items: map[string]*SimpleStruct{
+					"first": existing,
+				},
+			}
+
+			err := Copy("create", &src, &dst, WithMapOption("Items", option))
+			assert.NoError(t, err)
+
+			// The existing pointer is kept and updated in place
+			assert.Same(t, existing, dst.Items["first"])
+			assert.Equal(t, "X", existing.ID) // ID is protected
+			assert.Equal(t, "A", existing.Code)
+			assert.Equal(t, "First", existing.Name)
+
+			// New values are cloned
+			assert.NotSame(t, src.Items["second"], dst.Items["second"])
+			assert.Equal(t, *src.Items["second"], *dst.Items["second"])
+		})
+
+		t.Run(option+" with replace", func(t *testing.T) {
+			src := PointerMapStruct{
+				Items: map[string]*SimpleStruct{
+					"first": {ID: "1", Code: "A", Name: "First"},
+				},
+			}
+			existing := &SimpleStruct{ID: "X", Code: "Y", Name: "Existing"}
+			dst := PointerMapStruct{
+				Items: map[string]*SimpleStruct{
+					"first": existing,
+				},
+			}
+
+			err := Copy("create", &src, &dst, WithMapOption("Items", option), WithMapPointerReplace())
+			assert.NoError(t, err)
+
+			// The pointer is replaced and the original value is untouched
+			assert.NotSame(t, existing, dst.Items["first"])
+			assert.Equal(t, SimpleStruct{ID: "X", Code: "Y", Name: "Existing"}, *existing)
+			assert.Equal(t, "X", dst.Items["first"].ID) // ID is protected
+			assert.Equal(t, "A", dst.Items["first"].Code)
+			assert.Equal(t, "First", dst.Items["first"].Name)
+			assert.NotSame(t, src.Items["first"], dst.Items["first"])
+		})
+	}
+}
+
+func TestPointerSliceOptions(t *testing.T) {
+	t.Run("overwrite option", func(t *testing.T) {
+		src := []*SimpleStruct{
+			{ID: "1", Code: "A", Name: "First"},
+			{ID: "2", Code: "B", Name: "Second"},
+			nil,
+		}
+		dst := []*SimpleStruct{{ID: "X", Code: "Y", Name: "Existing"}}
+
+		err := CopySlice("create", &src, &dst, "overwrite")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 3, len(dst))
+		assert.NotSame(t, src[0], dst[0])
+		assert.Equal(t, *src[0], *dst[0]) // Tags are ignored
+		assert.Nil(t, dst[2])
+	})
+
+	t.Run("match option", func(t *testing.T) {
+		src := []*SimpleStruct{
+			{ID: "1", Code: "A", Name: "First"},
+			{ID: "2", Code: "B", Name: "Second"},
+			nil,
+		}
+		existing := &SimpleStruct{ID: "X", Code: "Y", Name: "Existing"}
+		dst := []*SimpleStruct{existing, nil}
+
+		err := CopySlice("create", &src, &dst, "match")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 3, len(dst))
+
+		// Existing elements are merged in place
+		assert.Same(t, existing, dst[0])
+		assert.Equal(t, "X", existing.ID) // ID is protected
+		assert.Equal(t, "First", existing.Name)
+
+		// Nil destination elements are allocated with protection
+		assert.NotNil(t, dst[1])
+		assert.NotSame(t, src[1], dst[1])
+		assert.Empty(t, dst[1].ID)
+		assert.Equal(t, "Second", dst[1].Name)
+
+		// Nil source elements stay nil
+		assert.Nil(t, dst[2])
+	})
+
+	t.Run("match option with new elements", func(t *testing.T) {
+		src := []*SimpleStruct{
+			{ID: "1", Code: "A", Name: "First"},
+			{ID: "2", Code: "B", Name: "Second"},
+			nil,
+		}
+		dst := []*SimpleStruct{}
+
+		err := CopySlice("create", &src, &dst, "match")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 3, len(dst))
+		assert.NotSame(t, src[0], dst[0])
+		assert.Empty(t, dst[0].ID)
+		assert.Equal(t, "First", dst[0].Name)
+		assert.Nil(t, dst[2])
+	})
+
+	t.Run("longer option", func(t *testing.T) {
+		src := []*SimpleStruct{
+			{ID: "1", Code: "A", Name: "First"},
+			{ID: "2", Code: "B", Name: "Second"},
+			nil,
+		}
+		existing := &SimpleStruct{ID: "X", Code: "Y", Name: "Existing"}
+		dst := []*SimpleStruct{existing}
+
+		err := CopySlice("create", &src, &dst, "longer")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 3, len(dst))
+		assert.Same(t, existing, dst[0])
+		assert.Equal(t, "X", existing.ID) // ID is protected
+		assert.Equal(t, "First", existing.Name)
+
+		// Extended elements are created with protection
+		assert.NotSame(t, src[1], dst[1])
+		assert.Equal(t, SimpleStruct{Code: "B", Name: "Second"}, *dst[1])
+		assert.Nil(t, dst[2])
+	})
+
+	t.Run("shorter option", func(t *testing.T) {
+		src := []*SimpleStruct{
+			{ID: "1", Code: "A", Name: "First"},
+			{ID: "2", Code: "B", Name: "Second"},
+			nil,
+		}
+		existing := &SimpleStruct{ID: "X", Code: "Y", Name: "Existing"}
+		dst := []*SimpleStruct{existing}
+
+		err := CopySlice("create", &src, &dst, "shorter")
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, len(dst))
+		assert.Same(t, existing, dst[0])
+		assert.Equal(t, "X", existing.ID) // ID is protected
+		assert.Equal(t, "First", existing.Name)
+	})
+}
+
+func TestCopyPure(t *testing.T) {
+	src := NestedStruct{
+		ID:     "123",
+		Parent: &SimpleStruct{ID: "p1", Code: "pc1", Name: "Parent"},
+	}
+	dst := NestedStruct{
+		ID:     "X",
+		Parent: &SimpleStruct{ID: "pX", Code: "pcX", Name: "Existing"},
+	}
+
+	result, err := CopyPure("update", &src, &dst)
+	assert.NoError(t, err)
+
+	merged := result.(*NestedStruct)
+	assert.Equal(t, "X", merged.ID) // ID is protected
+	assert.Equal(t, "pX", merged.Parent.ID)
+	assert.Equal(t, "Parent", merged.Parent.Name)
+
+	// dst is untouched
+	assert.Equal(t, "Existing", dst.Parent.Name)
+	assert.NotSame(t, dst.Parent, merged.Parent)
+
+	t.Run("dst passed by value", func(t *testing.T) {
+		_, err := CopyPure("update", &src, dst)
+		assert.ErrorIs(t, err, ErrDstNotPointer)
+	})
+
+	t.Run("type mismatch", func(t *testing.T) {
+		_, err := CopyPure("update", &SimpleStruct{}, &dst)
+		assert.Error(t, err)
+	})
+
+	t.Run("nil dst", func(t *testing.T) {
+		_, err := CopyPure("update", &src, (*NestedStruct)(nil))
+		assert.EqualError(t, err, "dst must not be nil pointer")
+	})
+}
+
+func TestNewFromTemplate(t *testing.T) {
+	template := &NestedStruct{
+		ID:     "default",
+		Parent: &SimpleStruct{Code: "C0", Name: "Default"},
+	}
+	overrides := &NestedStruct{
+		ID:     "client",
+		Parent: &SimpleStruct{ID: "client", Name: "Custom"},
+	}
+
+	result, err := NewFromTemplate("update", template, overrides)
+	assert.NoError(t, err)
+	created := result.(*NestedStruct)
+	assert.Equal(t, "default", created.ID) // ID is protected
+	assert.Equal(t, &SimpleStruct{Code: "C0", Name: "Custom"}, created.Parent)
+
+	// The template is untouched
+	assert.Equal(t, "Default", template.Parent.Name)
+	assert.NotSame(t, template.Parent, created.Parent)
+
+	t.Run("template passed by value", func(t *testing.T) {
+		_, err := NewFromTemplate("update", *template, overrides)
+		assert.ErrorIs(t, err, ErrDstNotPointer)
+	})
+
+	t.Run("nil template", func(t *testing.T) {
+		_, err := NewFromTemplate("update", (*NestedStruct)(nil), overrides)
+		assert.EqualError(t, err, "template must not be nil pointer")
+	})
+}
+
+func TestCopyFanOut(t *testing.T) {
+	src := NestedStruct{
+		ID:     "123",
+		Parent: &SimpleStruct{ID: "p1", Code: "pc1", Name: "Parent"},
+	}
+	cached := NestedStruct{ID: "X"}
+	response := NestedStruct{ID: "Y", Parent: &SimpleStruct{ID: "pY"}}
+
+	err := CopyFanOut("update", &src, []interface{}{&cached, &response})
+	assert.NoError(t, err)
+	assert.Equal(t, "X", cached.ID)
+	assert.Equal(t, "Parent", cached.Parent.Name)
+	assert.Equal(t, "Y", response.ID)
+	assert.Equal(t, "pY", response.Parent.ID)
+	assert.Equal(t, "Parent", response.Parent.Name)
+	assert.NotSame(t, cached.Parent, response.Parent)
+
+	t.Run("mismatched destinations", func(t *testing.T) {
+		dst := NestedStruct{ID: "X"}
+		err := CopyFanOut("update", &src, []interface{}{&dst, &SimpleStruct{}})
+		assert.ErrorContains(t, err, "dst 1")
+		// Nothing is copied
+		assert.Nil(t, dst.Parent)
+	})
+
+	t.Run("destination passed by value", func(t *testing.T) {
+		err := CopyFanOut("update", &src, []interface{}{NestedStruct{}})
+		assert.ErrorIs(t, err, ErrDstNotPointer)
+	})
+
+	t.Run("accessor called once", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		calls := 0
+		RegisterAccessor(p, func(src *encapsulatedUser) UserDTO {
+			calls++
+			return UserDTO{ID: src.GetID(), Name: src.GetName()}
+		})
+
+		var first, second UserDTO
+		err := p.CopyFanOut("create", &encapsulatedUser{id: "new", name: "New"}, []interface{}{&first, &second})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, UserDTO{ID: "new", Name: "New"}, first)
+		assert.Equal(t, UserDTO{ID: "new", Name: "New"}, second)
+	})
+
+	t.Run("with options", func(t *testing.T) {
+		src := SliceHolder{Items: []SimpleStruct{{ID: "N1", Name: "New"}}}
+		first := SliceHolder{Items: []SimpleStruct{{ID: "A1", Name: "Old"}}}
+		second := SliceHolder{Items: []SimpleStruct{{ID: "B1", Name: "Old"}}}
+		err := CopyFanOut("update", &src, []interface{}{&first, &second}, WithSliceOption("Items", "match"))
+		assert.NoError(t, err)
+		assert.Equal(t, []SimpleStruct{{ID: "A1", Name: "New"}}, first.Items)
+		assert.Equal(t, []SimpleStruct{{ID: "B1", Name: "New"}}, second.Items)
+	})
+}
+
+type NestedContainerMaps struct {
+	Lists  map[string][]SimpleStruct
+	Nested map[string]map[string]SimpleStruct
+	Values map[string]SliceHolder
+}
+
+func TestNestedContainerMapValues(t *testing.T) {
+	src := NestedContainerMaps{
+		Lists: map[string][]SimpleStruct{
+			"a":   {{ID: "N1", Name: "New"}},
+			"new": {{ID: "N2", Name: "New"}},
+		},
+		Nested: map[string]map[string]SimpleStruct{
+			"a":   {"x": {ID: "N3", Name: "New"}, "z": {ID: "N4", Name: "New"}},
+			"new": {"x": {ID: "N5", Name: "New"}},
+		},
+		Values: map[string]SliceHolder{
+			"a": {Items: []SimpleStruct{{ID: "N6", Name: "New"}}},
+		},
+	}
+
+	t.Run("map of slices", func(t *testing.T) {
+		for _, tc := range []struct {
+			mapOption   string
+			sliceOption string
+			expected    map[string][]SimpleStruct
+		}{
+			{"match", "overwrite", map[string][]SimpleStruct{
+				"a":   {{ID: "N1", Name: "New"}},
+				"new": {{ID: "N2", Name: "New"}},
+			}},
+			{"match", "match", map[string][]SimpleStruct{
+				"a":   {{ID: "A1", Name: "New"}},
+				"new": {{ID: "N2", Name: "New"}},
+			}},
+			{"patch", "longer", map[string][]SimpleStruct{
+				"a":    {{ID: "A1", Name: "New"}, {ID: "A2", Name: "Old"}},
+				"keep": {{ID: "K1", Name: "Old"}},
+				"new":  {{ID: "N2", Name: "New"}},
+			}},
+		} {
+			t.Run(tc.mapOption+"/"+tc.sliceOption, func(t *testing.T) {
+				dst := NestedContainerMaps{Lists: map[string][]SimpleStruct{
+					"a":    {{ID: "A1", Name: "Old"}, {ID: "A2", Name: "Old"}},
+					"keep": {{ID: "K1", Name: "Old"}},
+				}}
+				err := Copy("create", &src, &dst,
+					WithMapOption("Lists", tc.mapOption),
+					WithSliceOption("Lists[]", tc.sliceOption),
+				)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, dst.Lists)
+			})
+		}
+	})
+
+	t.Run("map of maps", func(t *testing.T) {
+		for _, tc := range []struct {
+			outer    string
+			inner    string
+			expected map[string]map[string]SimpleStruct
+		}{
+			{"match", "match", map[string]map[string]SimpleStruct{
+				"a":   {"x": {ID: "AX", Name: "New"}, "z": {ID: "N4", Name: "New"}},
+				"new": {"x": {ID: "N5", Name: "New"}},
+			}},
+			{"patch", "patch", map[string]map[string]SimpleStruct{
+				"a":    {"x": {ID: "AX", Name: "New"}, "y": {ID: "AY", Name: "Old"}, "z": {ID: "N4", Name: "New"}},
+				"keep": {"x": {ID: "KX", Name: "Old"}},
+				"new":  {"x": {ID: "N5", Name: "New"}},
+			}},
+		} {
+			t.Run(tc.outer+"/"+tc.inner, func(t *testing.T) {
+				dst := NestedContainerMaps{Nested: map[string]map[string]SimpleStruct{
+					"a":    {"x": {ID: "AX", Name: "Old"}, "y": {ID: "AY", Name: "Old"}},
+					"keep": {"x": {ID: "KX", Name: "Old"}},
+				}}
+				err := Copy("create", &src, &dst,
+					WithMapOption("Nested", tc.outer),
+					WithMapOption("Nested[]", tc.inner),
+				)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, dst.Nested)
+			})
+		}
+	})
+
+	t.Run("struct values with nested slices", func(t *testing.T) {
+		for _, option := range []string{"match", "patch"} {
+			t.Run(option, func(t *testing.T) {
+				dst := NestedContainerMaps{Values: map[string]SliceHolder{
+					"a": {Items: []SimpleStruct{{ID: "VA", Name: "Old"}}},
+				}}
+				err := Copy("create", &src, &dst,
+					WithMapOption("Values", option),
+					WithSliceOption("Values[].Items", "match"),
+				)
+				assert.NoError(t, err)
+				assert.Equal(t, []SimpleStruct{{ID: "VA", Name: "New"}}, dst.Values["a"].Items)
+
+				// The source is never aliased
+				dst.Values["a"].Items[0].Name = "Changed"
+				assert.Equal(t, "New", src.Values["a"].Items[0].Name)
+			})
+		}
+	})
+
+	t.Run("source is not aliased", func(t *testing.T) {
+		dst := NestedContainerMaps{
+			Lists:  map[string][]SimpleStruct{"a": {{ID: "A1", Name: "Old"}}},
+			Nested: map[string]map[string]SimpleStruct{"a": {"x": {ID: "AX", Name: "Old"}}},
+		}
+		err := Copy("create", &src, &dst, WithMapOption("Lists", "patch"), WithMapOption("Nested", "patch"))
+		assert.NoError(t, err)
+		dst.Lists["new"][0].Name = "Changed"
+		dst.Nested["new"]["x"] = SimpleStruct{Name: "Changed"}
+		assert.Equal(t, "New", src.Lists["new"][0].Name)
+		assert.Equal(t, "New", src.Nested["new"]["x"].Name)
+	})
+}
+
+func TestCloneWritable(t *testing.T) {
+	src := &SliceHolder{
+		Items: []SimpleStruct{{ID: "1", Code: "A", Name: "First"}},
+		Holders: []SliceHolder{
+			{Items: []SimpleStruct{{ID: "2", Code: "B", Name: "Second"}}},
+		},
+	}
+
+	clone := CloneWritable("update", src).(*SliceHolder)
+	assert.Equal(t, &SliceHolder{
+		Items: []SimpleStruct{{Name: "First"}},
+		Holders: []SliceHolder{
+			{Items: []SimpleStruct{{Name: "Second"}}},
+		},
+	}, clone)
+
+	// The source is untouched
+	assert.Equal(t, "1", src.Items[0].ID)
+
+	t.Run("maps and values", func(t *testing.T) {
+		m := map[string]SimpleStruct{"k": {ID: "1", Code: "A", Name: "First"}}
+		assert.Equal(t, map[string]SimpleStruct{"k": {Code: "A", Name: "First"}}, CloneWritable("create", m))
+		assert.Equal(t, SimpleStruct{Code: "A", Name: "First"}, CloneWritable("create", SimpleStruct{Code: "A", Name: "First"}))
+		assert.Nil(t, CloneWritable("create", nil))
+	})
+
+	t.Run("CloneFor", func(t *testing.T) {
+		assert.Equal(t, clone, CloneFor("update", src))
+	})
+}
+
+func TestDynamicStructs(t *testing.T) {
+	// Dynamic schemas built with reflect.StructOf carry protection tags like static types
+	typ := reflect.StructOf([]reflect.StructField{
+		{Name: "ID", Type: reflect.TypeOf(""), Tag: `protectfor:"update"`},
+		{Name: "Name", Type: reflect.TypeOf("")},
+		{Name: "Tags", Type: reflect.TypeOf([]string{})},
+	})
+
+	src := reflect.New(typ)
+	src.Elem().Field(0).SetString("new")
+	src.Elem().Field(1).SetString("New")
+	src.Elem().Field(2).Set(reflect.ValueOf([]string{"a"}))
+
+	dst := reflect.New(typ)
+	dst.Elem().Field(0).SetString("old")
+
+	err := Copy("update", src.Interface(), dst.Interface())
+	assert.NoError(t, err)
+	assert.Equal(t, "old", dst.Elem().Field(0).String())
+	assert.Equal(t, "New", dst.Elem().Field(1).String())
+	assert.Equal(t, []string{"a"}, dst.Elem().Field(2).Interface())
+
+	assert.Equal(t, []string{"ID"}, ProtectedFields("update", dst.Interface()))
+}
+
+type TaggedSliceOptions struct {
+	Matched   []SimpleStruct  `protectopt:"match"`
+	Longer    []SimpleStruct  `protectopt:"longer"`
+	Shorter   *[]SimpleStruct `protectopt:"shorter"`
+	Default   []SimpleStruct
+	Nested    [][]SimpleStruct `protectopt:"match"`
+	Overwrite []SimpleStruct   `protectopt:"overwrite"`
+}
+
+func TestSliceOptionTags(t *testing.T) {
+	items := []SimpleStruct{{ID: "N1", Name: "New"}}
+	shorter := []SimpleStruct{{ID: "N1", Name: "New"}, {ID: "N2", Name: "New"}, {ID: "N3", Name: "New"}}
+	src := TaggedSliceOptions{
+		Matched:   items,
+		Longer:    items,
+		Shorter:   &shorter,
+		Default:   items,
+		Nested:    [][]SimpleStruct{items},
+		Overwrite: items,
+	}
+
+	existingShorter := []SimpleStruct{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}}
+	dst := TaggedSliceOptions{
+		Matched:   []SimpleStruct{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}},
+		Longer:    []SimpleStruct{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}},
+		Shorter:   &existingShorter,
+		Default:   []SimpleStruct{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}},
+		Nested:    [][]SimpleStruct{{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}}},
+		Overwrite: []SimpleStruct{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}},
+	}
+	err := Copy("create", &src, &dst)
+	assert.NoError(t, err)
+	assert.Equal(t, []SimpleStruct{{ID: "X1", Name: "New"}}, dst.Matched)
+	assert.Equal(t, []SimpleStruct{{ID: "X1", Name: "New"}, {ID: "X2", Name: "Old"}}, dst.Longer)
+	assert.Equal(t, []SimpleStruct{{ID: "X1", Name: "New"}, {ID: "X2", Name: "New"}}, *dst.Shorter)
+	assert.Equal(t, items, dst.Default)
+	assert.Equal(t, items, dst.Overwrite)
+	// Options of a field don't apply to its elements
+	assert.Equal(t, [][]SimpleStruct{items}, dst.Nested)
+
+	t.Run("call options take precedence", func(t *testing.T) {
+		dst := TaggedSliceOptions{
+			Matched: []SimpleStruct{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}},
+			Default: []SimpleStruct{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}},
+		}
+		err := Copy("create", &src, &dst, WithSliceOption("Matched", "overwrite"), WithSliceOption("Default", "match"))
+		assert.NoError(t, err)
+		assert.Equal(t, items, dst.Matched)
+		assert.Equal(t, []SimpleStruct{{ID: "X1", Name: "New"}}, dst.Default)
+	})
+
+	t.Run("clones ignore options", func(t *testing.T) {
+		cloned := Clone(&src).(*TaggedSliceOptions)
+		assert.Equal(t, &src, cloned)
+		assert.NotSame(t, src.Shorter, cloned.Shorter)
+
+		clone, _, err := CloneWithStats(&src, WithSliceOption("Default", "shorter"))
+		assert.NoError(t, err)
+		assert.Equal(t, &src, clone)
+	})
+
+	t.Run("CopyPure keeps slices of dst", func(t *testing.T) {
+		shorter := []SimpleStruct{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}}
+		dst := TaggedSliceOptions{Shorter: &shorter}
+		result, err := CopyPure("create", &TaggedSliceOptions{Shorter: src.Shorter}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, []SimpleStruct{{ID: "X1", Name: "New"}, {ID: "X2", Name: "New"}}, *result.(*TaggedSliceOptions).Shorter)
+		assert.Equal(t, []SimpleStruct{{ID: "X1", Name: "Old"}, {ID: "X2", Name: "Old"}}, shorter)
+	})
+}
+
+type TaggedMapOptions struct {
+	Patched   map[string]SimpleStruct `protectopt:"patch"`
+	Matched   map[string]SimpleStruct `protectopt:"match"`
+	Overwrite map[string]SimpleStruct `protectopt:"overwrite"`
+	Default   map[string]SimpleStruct
+}
+
+func TestMapOptionTags(t *testing.T) {
+	values := map[string]SimpleStruct{"a": {ID: "N1", Name: "New"}}
+	src := TaggedMapOptions{
+		Patched:   values,
+		Matched:   values,
+		Overwrite: values,
+		Default:   values,
+	}
+
+	dst := TaggedMapOptions{
+		Patched:   map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}},
+		Matched:   map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}},
+		Overwrite: map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}},
+		Default:   map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}},
+	}
+	err := Copy("create", &src, &dst)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]SimpleStruct{"a": {ID: "X1", Name: "New"}, "b": {ID: "X2", Name: "Old"}}, dst.Patched)
+	assert.Equal(t, map[string]SimpleStruct{"a": {ID: "X1", Name: "New"}}, dst.Matched)
+	assert.Equal(t, values, dst.Overwrite)
+	assert.Equal(t, values, dst.Default)
+
+	t.Run("call options take precedence", func(t *testing.T) {
+		dst := TaggedMapOptions{
+			Patched: map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}},
+			Default: map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2", Name: "Old"}},
+		}
+		err := Copy("create", &src, &dst, WithMapOption("Patched", "overwrite"), WithMapOption("Default", "patch"))
+		assert.NoError(t, err)
+		assert.Equal(t, values, dst.Patched)
+		assert.Equal(t, map[string]SimpleStruct{"a": {ID: "X1", Name: "New"}, "b": {ID: "X2", Name: "Old"}}, dst.Default)
+	})
+
+	t.Run("clones ignore options", func(t *testing.T) {
+		assert.Equal(t, &src, Clone(&src))
+
+		clone, _, err := CloneWithStats(&src, WithMapOption("Default", "unknown"))
+		assert.NoError(t, err)
+		assert.Equal(t, &src, clone)
+	})
+}
+
+type SyncedMaps struct {
+	Labels map[string]string `protectopt:"sync"`
+	Values map[string]SimpleStruct
+}
+
+func TestSyncMapOption(t *testing.T) {
+	src := SyncedMaps{
+		Labels: map[string]string{"app": "new", "added": "y"},
+		Values: map[string]SimpleStruct{"a": {ID: "N1", Name: "New"}},
+	}
+
+	dst := SyncedMaps{
+		Labels: map[string]string{"app": "old", "removed": "x", "managed-by": "server"},
+		Values: map[string]SimpleStruct{"a": {ID: "X1", Name: "Old"}, "b": {ID: "X2"}},
+	}
+	labels := dst.Labels
+	err := Copy("update", &src, &dst, WithKeepKeys("Labels", "managed-by"), WithMapOption("Values", "sync"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "new", "added": "y", "managed-by": "server"}, dst.Labels)
+	// The map is updated in place
+	assert.Equal(t, reflect.ValueOf(labels).Pointer(), reflect.ValueOf(dst.Labels).Pointer())
+	assert.Equal(t, map[string]SimpleStruct{"a": {ID: "X1", Name: "New"}}, dst.Values)
+
+	t.Run("without kept keys", func(t *testing.T) {
+		dst := SyncedMaps{
+			Labels: map[string]string{"app": "old", "removed": "x", "managed-by": "server"},
+		}
+		err := CopyMap("update", &src.Labels, &dst.Labels, "sync")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"app": "new", "added": "y"}, dst.Labels)
+	})
+}
+
+type RoleHolder struct {
+	Roles []string `protectopt:"set"`
+	IDs   []int
+}
+
+func TestSetSliceOption(t *testing.T) {
+	dst := RoleHolder{Roles: []string{"admin", "editor", "admin"}, IDs: []int{3, 1}}
+	src := RoleHolder{Roles: []string{"viewer", "editor", "viewer"}, IDs: []int{1, 2, 2}}
+
+	err := Copy("update", &src, &dst, WithSliceOption("IDs", "set"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin", "editor", "viewer"}, dst.Roles)
+	assert.Equal(t, []int{3, 1, 2}, dst.IDs)
+
+	t.Run("repeated updates", func(t *testing.T) {
+		err := Copy("update", &src, &dst, WithSliceOption("IDs", "set"))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"admin", "editor", "viewer"}, dst.Roles)
+		assert.Equal(t, []int{3, 1, 2}, dst.IDs)
+	})
+
+	t.Run("clones keep duplicates", func(t *testing.T) {
+		holder := RoleHolder{Roles: []string{"admin", "editor", "admin"}}
+		assert.Equal(t, &holder, Clone(&holder))
+
+		result, err := CopyPure("update", &RoleHolder{Roles: []string{"viewer"}}, &holder)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"admin", "editor", "viewer"}, result.(*RoleHolder).Roles)
+	})
+
+	t.Run("source slice is not shared", func(t *testing.T) {
+		var dst []string
+		src := []string{"a"}
+		err := CopySlice("update", &src, &dst, "set")
+		assert.NoError(t, err)
+		dst[0] = "b"
+		assert.Equal(t, []string{"a"}, src)
+	})
+
+	t.Run("incomparable elements", func(t *testing.T) {
+		dst := [][]string{}
+		err := CopySlice("update", &[][]string{{"a"}}, &dst, "set")
+		assert.ErrorContains(t, err, "comparable")
+
+		values := []interface{}{}
+		err = CopySlice("update", &[]interface{}{[]string{"a"}}, &values, "set")
+		assert.ErrorContains(t, err, "comparable")
+
+		type tagged struct {
+			Value interface{}
+		}
+		structs := []tagged{}
+		err = CopySlice("update", &[]tagged{{Value: []int{1}}}, &structs, "set")
+		assert.ErrorContains(t, err, "comparable")
+
+		arrays := [][1]interface{}{}
+		err = CopySlice("update", &[][1]interface{}{{[]int{1}}}, &arrays, "set")
+		assert.ErrorContains(t, err, "comparable")
+	})
+}
+
+type KeyedItems struct {
+	Items []SimpleStruct `protectopt:"matchbykey=ID"`
+}
+
+func TestMatchByKey(t *testing.T) {
+	dst := KeyedItems{Items: []SimpleStruct{
+		{ID: "1", Code: "A", Name: "First"},
+		{ID: "2", Code: "B", Name: "Second"},
+	}}
+	src := KeyedItems{Items: []SimpleStruct{
+		{ID: "2", Code: "X", Name: "Second updated"},
+		{ID: "3", Code: "Y", Name: "Inserted"},
+		{ID: "1", Code: "Z", Name: "First updated"},
+	}}
+
+	err := Copy("update", &src, &dst)
+	assert.NoError(t, err)
+	assert.Equal(t, []SimpleStruct{
+		{ID: "2", Code: "B", Name: "Second updated"},
+		{Name: "Inserted"},
+		{ID: "1", Code: "A", Name: "First updated"},
+	}, dst.Items)
+
+	t.Run("removed elements", func(t *testing.T) {
+		dst := []SimpleStruct{{ID: "1", Code: "A"}, {ID: "2", Code: "B"}}
+		err := CopySlice("update", &[]SimpleStruct{{ID: "2", Name: "Kept"}}, &dst, "matchbykey=ID")
+		assert.NoError(t, err)
+		assert.Equal(t, []SimpleStruct{{ID: "2", Code: "B", Name: "Kept"}}, dst)
+	})
+
+	t.Run("pointer elements are merged in place", func(t *testing.T) {
+		existing := &SimpleStruct{ID: "1", Code: "A", Name: "Old"}
+		dst := []*SimpleStruct{existing}
+		src := []*SimpleStruct{{ID: "1", Code: "X", Name: "New"}, nil}
+		err := CopySlice("update", &src, &dst, "matchbykey=ID")
+		assert.NoError(t, err)
+		assert.Len(t, dst, 2)
+		assert.Same(t, existing, dst[0])
+		assert.Equal(t, SimpleStruct{ID: "1", Code: "A", Name: "New"}, *existing)
+		assert.Nil(t, dst[1])
+	})
+
+	t.Run("call options", func(t *testing.T) {
+		dst := SliceHolder{Items: []SimpleStruct{{ID: "1", Code: "A"}}}
+		src := SliceHolder{Items: []SimpleStruct{{ID: "2"}, {ID: "1", Name: "Matched"}}}
+		err := Copy("update", &src, &dst, WithSliceOption("Items", "matchbykey=ID"))
+		assert.NoError(t, err)
+		assert.Equal(t, []SimpleStruct{{}, {ID: "1", Code: "A", Name: "Matched"}}, dst.Items)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		dst := []SimpleStruct{{ID: "1"}}
+		err := CopySlice("update", &[]SimpleStruct{{ID: "1"}}, &dst, "matchbykey=Missing")
+		assert.ErrorContains(t, err, "no field Missing")
+
+		names := []string{"a"}
+		err = CopySlice("update", &[]string{"a"}, &names, "matchbykey=ID")
+		assert.ErrorContains(t, err, "requires struct elements")
+
+		type anyKeyed struct {
+			Key interface{}
+		}
+		keyed := []anyKeyed{}
+		err = CopySlice("update", &[]anyKeyed{{Key: []int{1}}}, &keyed, "matchbykey=Key")
+		assert.ErrorContains(t, err, "not comparable")
+	})
+
+	t.Run("clones ignore the option", func(t *testing.T) {
+		assert.Equal(t, &src, Clone(&src))
+
+		type invalidKeyed struct {
+			Items []SimpleStruct `protectopt:"matchbykey=Missing"`
+		}
+		invalid := invalidKeyed{Items: []SimpleStruct{{ID: "1"}}}
+		clone, _, err := CloneWithStats(&invalid)
+		assert.NoError(t, err)
+		assert.Equal(t, &invalid, clone)
+	})
+}
+
+type AuditFields struct {
+	ID        string
+	CreatedAt string
+	Note      string
+}
+
+type PromotedTagStruct struct {
+	AuditFields `protectfor:"ID=create|update,CreatedAt=update"`
+	Name        string
+}
+
+type OverriddenTagStruct struct {
+	SimpleStruct `protectfor:"ID=,Code=create"`
+}
+
+func TestPromotedFieldTags(t *testing.T) {
+	t.Run("copy", func(t *testing.T) {
+		dst := PromotedTagStruct{AuditFields: AuditFields{ID: "1", CreatedAt: "old", Note: "old"}, Name: "old"}
+		src := PromotedTagStruct{AuditFields: AuditFields{ID: "2", CreatedAt: "new", Note: "new"}, Name: "new"}
+		err := Copy("update", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, PromotedTagStruct{AuditFields: AuditFields{ID: "1", CreatedAt: "old", Note: "new"}, Name: "new"}, dst)
+
+		dst = PromotedTagStruct{AuditFields: AuditFields{CreatedAt: "old"}}
+		err = Copy("create", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, PromotedTagStruct{AuditFields: AuditFields{CreatedAt: "new", Note: "new"}, Name: "new"}, dst)
+	})
+
+	t.Run("writable clone", func(t *testing.T) {
+		src := &PromotedTagStruct{AuditFields: AuditFields{ID: "1", CreatedAt: "now", Note: "note"}, Name: "name"}
+		clone := CloneWritable("update", src).(*PromotedTagStruct)
+		assert.Equal(t, &PromotedTagStruct{AuditFields: AuditFields{Note: "note"}, Name: "name"}, clone)
+	})
+
+	t.Run("outer tags override inner tags", func(t *testing.T) {
+		dst := OverriddenTagStruct{SimpleStruct{ID: "1", Code: "A", Name: "old"}}
+		err := Copy("update", &OverriddenTagStruct{SimpleStruct{ID: "2", Code: "B", Name: "new"}}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, OverriddenTagStruct{SimpleStruct{ID: "2", Code: "B", Name: "new"}}, dst)
+		assert.Equal(t, []string{"SimpleStruct.Code"}, ProtectedFields("create", OverriddenTagStruct{}))
+	})
+
+	t.Run("combined tags", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		p.SetCombineEmbeddedTags(true)
+		dst := OverriddenTagStruct{SimpleStruct{ID: "1", Code: "A", Name: "old"}}
+		err := p.Copy("update", &OverriddenTagStruct{SimpleStruct{ID: "2", Code: "B", Name: "new"}}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, OverriddenTagStruct{SimpleStruct{ID: "1", Code: "A", Name: "new"}}, dst)
+		assert.Equal(t, []string{"SimpleStruct.ID", "SimpleStruct.Code"}, p.ProtectedFields("create", OverriddenTagStruct{}))
+	})
+
+	t.Run("protected fields", func(t *testing.T) {
+		assert.Equal(t, []string{"AuditFields.ID", "AuditFields.CreatedAt"}, ProtectedFields("update", PromotedTagStruct{}))
+		assert.Equal(t, []string{"AuditFields.ID"}, ProtectedFields("create", PromotedTagStruct{}))
+	})
+}
+
+type ImmutableStruct struct {
+	ID   string `protectfor:"*"`
+	Name string
+}
+
+func TestWildcardTag(t *testing.T) {
+	for _, tag := range []string{"create", "update", "other"} {
+		dst := ImmutableStruct{ID: "1", Name: "old"}
+		err := Copy(tag, &ImmutableStruct{ID: "2", Name: "new"}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, ImmutableStruct{ID: "1", Name: "new"}, dst, tag)
+		assert.Equal(t, []string{"ID"}, ProtectedFields(tag, ImmutableStruct{}), tag)
+	}
+
+	t.Run("no tag copies everything", func(t *testing.T) {
+		var dst ImmutableStruct
+		err := Copy("", &ImmutableStruct{ID: "2", Name: "new"}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, ImmutableStruct{ID: "2", Name: "new"}, dst)
+	})
+
+	t.Run("descriptor", func(t *testing.T) {
+		d := Describe(ImmutableStruct{})
+		assert.False(t, d.IsWritable("update", "ID"))
+		assert.True(t, d.IsWritable("update", "Name"))
+	})
+}
+
+type CreateOnlyStruct struct {
+	Owner string `protectfor:"!create"`
+	Plan  string `protectfor:"!create,!import,admin"`
+	Name  string
+}
+
+func TestNegatedTags(t *testing.T) {
+	src := CreateOnlyStruct{Owner: "new", Plan: "new", Name: "new"}
+
+	tests := []struct {
+		tag  string
+		want CreateOnlyStruct
+	}{
+		{tag: "create", want: CreateOnlyStruct{Owner: "new", Plan: "new", Name: "new"}},
+		{tag: "import", want: CreateOnlyStruct{Owner: "old", Plan: "new", Name: "new"}},
+		{tag: "update", want: CreateOnlyStruct{Owner: "old", Plan: "old", Name: "new"}},
+		{tag: "admin", want: CreateOnlyStruct{Owner: "old", Plan: "old", Name: "new"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			dst := CreateOnlyStruct{Owner: "old", Plan: "old", Name: "old"}
+			err := Copy(tt.tag, &src, &dst)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, dst)
+		})
+	}
+
+	t.Run("descriptor", func(t *testing.T) {
+		d := Describe(CreateOnlyStruct{})
+		assert.True(t, d.IsWritable("create", "Owner"))
+		assert.False(t, d.IsWritable("update", "Owner"))
+	})
+
+	t.Run("combined with tags of the embedding struct", func(t *testing.T) {
+		type ImportedStruct struct {
+			CreateOnlyStruct `protectfor:"Owner=!import"`
+		}
+		p := NewProtector("protectfor", "protectopt")
+		p.SetCombineEmbeddedTags(true)
+
+		for _, tag := range []string{"create", "import", "update"} {
+			dst := ImportedStruct{CreateOnlyStruct{Owner: "old"}}
+			err := p.Copy(tag, &ImportedStruct{CreateOnlyStruct{Owner: "new"}}, &dst)
+			assert.NoError(t, err)
+			assert.Equal(t, "old", dst.Owner, tag)
+			assert.Contains(t, p.ProtectedFields(tag, ImportedStruct{}), "CreateOnlyStruct.Owner", tag)
+			assert.False(t, p.Describe(ImportedStruct{}).IsWritable(tag, "CreateOnlyStruct.Owner"), tag)
+		}
+	})
+}
+
+type AllowlistItem struct {
+	ID   string
+	Name string `copyfor:"create,update"`
+}
+
+type AllowlistStruct struct {
+	ID    string
+	Name  string          `copyfor:"create,update"`
+	Email string          `copyfor:"create,update" protectfor:"update"`
+	Items []AllowlistItem `copyfor:"update" protectopt:"match"`
+}
+
+func TestAllowlistTag(t *testing.T) {
+	p := NewProtector("protectfor", "protectopt")
+	p.SetAllowlistTag("copyfor")
+
+	src := AllowlistStruct{
+		ID:    "new",
+		Name:  "new",
+		Email: "new",
+		Items: []AllowlistItem{{ID: "new", Name: "new"}},
+	}
+
+	t.Run("copy", func(t *testing.T) {
+		dst := AllowlistStruct{ID: "old", Name: "old", Email: "old", Items: []AllowlistItem{{ID: "old"}}}
+		err := p.Copy("update", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, AllowlistStruct{
+			ID:    "old",
+			Name:  "new",
+			Email: "old",
+			Items: []AllowlistItem{{ID: "old", Name: "new"}},
+		}, dst)
+
+		dst = AllowlistStruct{}
+		err = p.Copy("delete", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, AllowlistStruct{}, dst)
+	})
+
+	t.Run("no tag copies everything", func(t *testing.T) {
+		var dst AllowlistStruct
+		err := p.Copy("", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, src, dst)
+	})
+
+	t.Run("protected fields", func(t *testing.T) {
+		assert.Equal(t, []string{"ID", "Items"}, p.ProtectedFields("create", AllowlistStruct{}))
+		assert.Equal(t, []string{"ID", "Email", "Items[].ID"}, p.ProtectedFields("update", AllowlistStruct{}))
+
+		d := p.Describe(AllowlistStruct{})
+		assert.False(t, d.IsWritable("update", "ID"))
+		assert.True(t, d.IsWritable("update", "Items[].Name"))
+	})
+
+	t.Run("negated protection tags still protect", func(t *testing.T) {
+		type CreateOnly struct {
+			Owner string `copyfor:"create,update" protectfor:"!create"`
+		}
+		dst := CreateOnly{Owner: "old"}
+		err := p.Copy("update", &CreateOnly{Owner: "new"}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, CreateOnly{Owner: "old"}, dst)
+
+		err = p.Copy("create", &CreateOnly{Owner: "new"}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, CreateOnly{Owner: "new"}, dst)
+
+		d := p.Describe(CreateOnly{})
+		assert.Equal(t, []FieldDescriptor{
+			{Path: "Owner", JSONPath: "Owner", ProtectFor: []string{"!create"}, CopyFor: []string{"create", "update"}},
+		}, d.Fields)
+		assert.False(t, d.IsWritable("update", "Owner"))
+		assert.True(t, d.IsWritable("create", "Owner"))
+		assert.False(t, d.IsWritable("delete", "Owner"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		p.SetAllowlistTag("copyfor")
+		p.SetAllowlistTag("")
+		var dst AllowlistStruct
+		err := p.Copy("update", &src, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, AllowlistStruct{ID: "new", Name: "new", Items: src.Items}, dst)
+	})
+}
@@ -0,0 +1,88 @@
+// Package protectcache provides an in-process cache cloning values on the way in and out,
+// preventing the classic bug of cached values being mutated by handlers.
+package protectcache
+
+import (
+	"sync"
+
+	"github.com/ikedam/protect"
+)
+
+// Cache is an in-process cache holding deep copies of values.
+// Values are cloned with the Protector when they are set and got,
+// so modifying values set to or got from the cache never affects the cached values.
+// Primitive structs registered to the Protector are copied at once.
+// It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	protector *protect.Protector
+
+	mu      sync.RWMutex
+	entries map[K]V
+}
+
+// New creates a Cache cloning values with p. protect.DefaultProtector is used if p is nil.
+func New[K comparable, V any](p *protect.Protector) *Cache[K, V] {
+	if p == nil {
+		p = protect.DefaultProtector
+	}
+	return &Cache[K, V]{
+		protector: p,
+		entries:   make(map[K]V),
+	}
+}
+
+// Get returns a deep copy of the value for the key, and whether it is cached.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	v, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok {
+		return v, false
+	}
+	// Cached values are never modified, so they can be cloned outside the lock
+	clone, err := c.clone(v)
+	if err != nil {
+		// Cached values were cloned in Set, so cloning them again never fails
+		var zero V
+		return zero, false
+	}
+	return clone, true
+}
+
+// Set caches a deep copy of the value for the key.
+// Values with cycles are cloned with the same cycles.
+// It returns an error if the value cannot be cloned.
+func (c *Cache[K, V]) Set(key K, v V) error {
+	clone, err := c.clone(v)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = clone
+	return nil
+}
+
+// Delete removes the value for the key.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Len returns the number of cached values.
+func (c *Cache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// clone returns a deep copy of v.
+func (c *Cache[K, V]) clone(v V) (V, error) {
+	clone, _, err := c.protector.CloneWithStats(&v)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return *clone.(*V), nil
+}
@@ -0,0 +1,94 @@
+package protectcache
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/ikedam/protect"
+	"github.com/stretchr/testify/assert"
+)
+
+type Item struct {
+	ID   string `protectfor:"update"`
+	Tags []string
+}
+
+func TestCache(t *testing.T) {
+	c := New[string, *Item](nil)
+
+	item := &Item{ID: "1", Tags: []string{"a"}}
+	assert.NoError(t, c.Set("1", item))
+
+	// Modifying the value set to the cache doesn't affect the cached value
+	item.Tags[0] = "modified"
+
+	got, ok := c.Get("1")
+	assert.True(t, ok)
+	assert.Equal(t, &Item{ID: "1", Tags: []string{"a"}}, got)
+
+	// Modifying the value got from the cache doesn't affect the cached value
+	got.Tags = append(got.Tags, "b")
+	got2, _ := c.Get("1")
+	assert.Equal(t, []string{"a"}, got2.Tags)
+	assert.NotSame(t, got, got2)
+
+	assert.Equal(t, 1, c.Len())
+	c.Delete("1")
+	_, ok = c.Get("1")
+	assert.False(t, ok)
+
+	t.Run("nil values", func(t *testing.T) {
+		assert.NoError(t, c.Set("nil", nil))
+		got, ok := c.Get("nil")
+		assert.True(t, ok)
+		assert.Nil(t, got)
+	})
+
+	t.Run("custom protector", func(t *testing.T) {
+		c := New[int, Item](protect.NewProtector("protectfor", "protectopt"))
+		assert.NoError(t, c.Set(1, Item{ID: "1"}))
+		got, ok := c.Get(1)
+		assert.True(t, ok)
+		assert.Equal(t, "1", got.ID)
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		type Node struct {
+			Name string
+			Next *Node
+		}
+		c := New[string, *Node](nil)
+		node := &Node{Name: "a"}
+		node.Next = node
+		assert.NoError(t, c.Set("a", node))
+		got, ok := c.Get("a")
+		assert.True(t, ok)
+		assert.NotSame(t, node, got)
+		assert.Same(t, got, got.Next)
+	})
+
+	t.Run("values with slice options", func(t *testing.T) {
+		type Tagged struct {
+			Items []Item `protectopt:"shorter"`
+		}
+		c := New[string, Tagged](nil)
+		v := Tagged{Items: []Item{{ID: "1"}, {ID: "2"}}}
+		assert.NoError(t, c.Set("tagged", v))
+		got, ok := c.Get("tagged")
+		assert.True(t, ok)
+		assert.Equal(t, v, got)
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				c.Set("k", &Item{ID: "k"})
+				c.Get("k")
+			}(i)
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,121 @@
+package protectecho
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BulkPatch declares how a JSON array of patches is applied to existing values for collection PATCH endpoints.
+// Each patch carries the key of its target, e.g. `[{"id":"1","name":"A"},{"id":"2","name":"B"}]`.
+type BulkPatch[T any] struct {
+	// Tag is the tag to protect fields when applying patches.
+	Tag string
+	// Key returns the key of the target from a patch decoded into a new T.
+	Key func(v *T) string
+	// Load returns the targets by their keys in a single call, e.g. with one database query.
+	// Keys are unique and in the order of the patches.
+	// Keys without targets are reported as not found.
+	Load func(c echo.Context, keys []string) (map[string]*T, error)
+}
+
+// BulkResult is the result of a patch in BulkPatch.
+type BulkResult[T any] struct {
+	// Key is the key of the target. It is empty if the patch has no key.
+	Key string `json:"key"`
+	// Status is the HTTP status code for the patch: 200 for success,
+	// 400 for invalid patches, 404 for missing targets and 500 for targets failing to clone.
+	Status int `json:"status"`
+	// Value is the patched target. It is nil unless the patch succeeded.
+	Value *T `json:"value,omitempty"`
+	// Error is the description of the error for the patch.
+	Error string `json:"error,omitempty"`
+}
+
+// Apply applies the patches in the request body to the targets returned by BulkPatch.Load.
+// Each patch is bound onto a clone of its target and copied back with protection by BulkPatch.Tag,
+// as Bind does, so fields absent in the patch keep their values.
+// Failures of patches are reported in the results without stopping the others,
+// and the targets are modified only for successful patches.
+// Persist the targets of successful results and respond with the results, e.g. with 207 Multi-Status.
+// The request body must be a JSON array; an error is returned as *echo.HTTPError with status 400 if it isn't.
+func (b BulkPatch[T]) Apply(c echo.Context) ([]BulkResult[T], error) {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var patches []json.RawMessage
+	if err := json.Unmarshal(body, &patches); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "request body must be a JSON array of patches").SetInternal(err)
+	}
+
+	results := make([]BulkResult[T], len(patches))
+	var keys []string
+	seen := make(map[string]bool)
+	for i, patch := range patches {
+		v := new(T)
+		if err := json.Unmarshal(patch, v); err != nil {
+			results[i] = BulkResult[T]{Status: http.StatusBadRequest, Error: err.Error()}
+			continue
+		}
+		key := b.Key(v)
+		if key == "" {
+			results[i] = BulkResult[T]{Status: http.StatusBadRequest, Error: "missing key"}
+			continue
+		}
+		results[i].Key = key
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	targets, err := b.Load(c, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	p := GetProtector(c)
+	for i, patch := range patches {
+		if results[i].Key == "" {
+			continue
+		}
+		target, ok := targets[results[i].Key]
+		if !ok || target == nil {
+			results[i].Status = http.StatusNotFound
+			results[i].Error = "not found"
+			continue
+		}
+
+		// Bind the patch to a clone of the target
+		cloned, _, err := p.CloneWithStats(target)
+		if err != nil {
+			results[i].Status = http.StatusInternalServerError
+			results[i].Error = err.Error()
+			continue
+		}
+		clone := cloned.(*T)
+		if err := json.Unmarshal(patch, clone); err != nil {
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = err.Error()
+			continue
+		}
+
+		// Apply protection rules to a clone again so that failures never leave the target partially patched
+		patched, err := p.CopyPure(b.Tag, clone, target)
+		if err != nil {
+			results[i].Status = http.StatusBadRequest
+			results[i].Error = err.Error()
+			continue
+		}
+		*target = *patched.(*T)
+
+		results[i].Status = http.StatusOK
+		results[i].Value = target
+	}
+
+	return results, nil
+}
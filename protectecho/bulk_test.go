@@ -0,0 +1,109 @@
+package protectecho
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBulkContext(body string) echo.Context {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPatch, "/items", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	return e.NewContext(req, httptest.NewRecorder())
+}
+
+func TestBulkPatch(t *testing.T) {
+	newBulk := func(stored map[string]*TestStruct, loaded *[]string) BulkPatch[TestStruct] {
+		return BulkPatch[TestStruct]{
+			Tag: "update",
+			Key: func(v *TestStruct) string { return v.ID },
+			Load: func(c echo.Context, keys []string) (map[string]*TestStruct, error) {
+				*loaded = keys
+				return stored, nil
+			},
+		}
+	}
+
+	t.Run("per element results", func(t *testing.T) {
+		stored := map[string]*TestStruct{
+			"1": {ID: "1", Code: "C1", Name: "First"},
+			"2": {ID: "2", Code: "C2", Name: "Second"},
+		}
+		var loaded []string
+		c := newBulkContext(`[
+			{"id":"1","code":"X","name":"Updated"},
+			{"id":"2"},
+			{"id":"3","name":"Missing"},
+			{"name":"No key"},
+			{"id":"1","name":1},
+			{"id":"2","name":"Second patch"}
+		]`)
+
+		results, err := newBulk(stored, &loaded).Apply(c)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1", "2", "3"}, loaded)
+
+		assert.Equal(t, []BulkResult[TestStruct]{
+			{Key: "1", Status: http.StatusOK, Value: stored["1"]},
+			{Key: "2", Status: http.StatusOK, Value: stored["2"]},
+			{Key: "3", Status: http.StatusNotFound, Error: "not found"},
+			{Status: http.StatusBadRequest, Error: "missing key"},
+		}, results[:4])
+		assert.Equal(t, http.StatusBadRequest, results[4].Status)
+		assert.Equal(t, http.StatusOK, results[5].Status)
+
+		// Protected fields are kept and absent fields keep their values
+		assert.Equal(t, &TestStruct{ID: "1", Code: "C1", Name: "Updated"}, stored["1"])
+		// Patches to the same target are applied in order
+		assert.Equal(t, &TestStruct{ID: "2", Code: "C2", Name: "Second patch"}, stored["2"])
+	})
+
+	t.Run("not an array", func(t *testing.T) {
+		var loaded []string
+		_, err := newBulk(nil, &loaded).Apply(newBulkContext(`{"id":"1"}`))
+		var httpErr *echo.HTTPError
+		assert.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("targets with slice options", func(t *testing.T) {
+		type Tagged struct {
+			ID   string   `json:"id"`
+			Name string   `json:"name"`
+			Tags []string `json:"tags" protectopt:"shorter"`
+		}
+		stored := &Tagged{ID: "1", Tags: []string{"a", "b"}}
+		bulk := BulkPatch[Tagged]{
+			Tag: "update",
+			Key: func(v *Tagged) string { return v.ID },
+			Load: func(c echo.Context, keys []string) (map[string]*Tagged, error) {
+				return map[string]*Tagged{"1": stored}, nil
+			},
+		}
+		results, err := bulk.Apply(newBulkContext(`[{"id":"1","name":"n"}]`))
+		assert.NoError(t, err)
+		if assert.Len(t, results, 1) {
+			assert.Equal(t, http.StatusOK, results[0].Status)
+			assert.Equal(t, &Tagged{ID: "1", Name: "n", Tags: []string{"a", "b"}}, stored)
+		}
+	})
+
+	t.Run("load error", func(t *testing.T) {
+		loadErr := errors.New("database down")
+		bulk := BulkPatch[TestStruct]{
+			Tag: "update",
+			Key: func(v *TestStruct) string { return v.ID },
+			Load: func(c echo.Context, keys []string) (map[string]*TestStruct, error) {
+				return nil, loadErr
+			},
+		}
+		_, err := bulk.Apply(newBulkContext(`[{"id":"1"}]`))
+		assert.ErrorIs(t, err, loadErr)
+	})
+}
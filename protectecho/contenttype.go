@@ -0,0 +1,65 @@
+package protectecho
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RequireContentType returns a middleware rejecting requests with a body
+// whose Content-Type doesn't match any of mediaTypes with status 415.
+// A media type also matches vendor types with the same structured syntax suffix:
+// "application/json" accepts "application/vnd.myapp+json".
+// The Content-Type of such requests is rewritten to the matched media type
+// so that echo's binder decodes the body in that format.
+// Requests without a body are passed through.
+//
+// Apply it before handlers calling ReBindable or Bind so that bodies are rejected
+// before they are buffered and decoded by content sniffing.
+func RequireContentType(mediaTypes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength == 0 || req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+
+			mediaType, params, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+			if err != nil {
+				return echo.ErrUnsupportedMediaType
+			}
+			matched, ok := matchMediaType(mediaType, mediaTypes)
+			if !ok {
+				return echo.ErrUnsupportedMediaType
+			}
+			if matched != mediaType {
+				req.Header.Set(echo.HeaderContentType, mime.FormatMediaType(matched, params))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// matchMediaType returns the media type in allowed matching mediaType.
+func matchMediaType(mediaType string, allowed []string) (string, bool) {
+	mediaType = strings.ToLower(mediaType)
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	_, suffix, hasSuffix := strings.Cut(subtype, "+")
+
+	for _, a := range allowed {
+		a = strings.ToLower(a)
+		if a == mediaType {
+			return a, true
+		}
+		// Match vendor types by the structured syntax suffix
+		allowedType, allowedSubtype, _ := strings.Cut(a, "/")
+		if hasSuffix && allowedType == typ && allowedSubtype == suffix {
+			return a, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,55 @@
+package protectecho
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireContentType(t *testing.T) {
+	newServer := func() *echo.Echo {
+		e := echo.New()
+		e.POST("/", func(c echo.Context) error {
+			c = ReBindable(c)
+			dst := TestStruct{}
+			if err := Bind("create", c, &dst); err != nil {
+				return err
+			}
+			return c.String(http.StatusOK, dst.Name)
+		}, RequireContentType(echo.MIMEApplicationJSON))
+		return e
+	}
+
+	testCases := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"JSON", "application/json", `{"name":"Test"}`, http.StatusOK},
+		{"JSON with charset", "application/json; charset=UTF-8", `{"name":"Test"}`, http.StatusOK},
+		{"Vendor JSON", "application/vnd.myapp+json", `{"name":"Test"}`, http.StatusOK},
+		{"Form", "application/x-www-form-urlencoded", `name=Test`, http.StatusUnsupportedMediaType},
+		{"Vendor XML", "application/vnd.myapp+xml", `<name>Test</name>`, http.StatusUnsupportedMediaType},
+		{"Missing", "", `{"name":"Test"}`, http.StatusUnsupportedMediaType},
+		{"No body", "", ``, http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newServer()
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set(echo.HeaderContentType, tc.contentType)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package protectecho
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Endpoint declares how a request is bound to T and handled.
+type Endpoint[T any] struct {
+	// Tag is the tag to protect fields when binding the request.
+	Tag string
+	// SliceOpt is the slice option used when T is a slice.
+	// See BindSlice for available values. Defaults to "overwrite".
+	SliceOpt string
+	// Validate calls echo.Context.Validate() on the bound value when true.
+	Validate bool
+	// Load returns the value to bind the request onto, e.g. the entity fetched from the database.
+	// A new zero value is used if Load is nil.
+	Load func(c echo.Context) (*T, error)
+	// Handler handles the request with the bound value.
+	Handler func(c echo.Context, v *T) error
+}
+
+// Handle registers the endpoint to e.
+// route is a method and a path separated by a space, like "PUT /items/:id".
+// The request is bound with protection by Endpoint.Tag and then passed to Endpoint.Handler.
+// Binding errors are returned as is (echo's binder already reports them as *echo.HTTPError),
+// and validation errors are returned as *echo.HTTPError with status 400.
+// The route is declared with its bind type for CheckRoutes.
+func Handle[T any](e *echo.Echo, route string, endpoint Endpoint[T], m ...echo.MiddlewareFunc) *echo.Route {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	if !ok {
+		panic(fmt.Sprintf("protectecho: invalid route %q: must be \"METHOD /path\"", route))
+	}
+	r := e.Add(strings.ToUpper(method), strings.TrimSpace(path), endpoint.handlerFunc(), m...)
+	registerRoute(e, r.Method, r.Path, routeDeclaration{
+		tag:      endpoint.Tag,
+		bindType: reflect.TypeOf((*T)(nil)).Elem(),
+	})
+	return r
+}
+
+// handlerFunc builds the echo.HandlerFunc for the endpoint.
+func (endpoint Endpoint[T]) handlerFunc() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c = ReBindable(c)
+
+		v := new(T)
+		if endpoint.Load != nil {
+			loaded, err := endpoint.Load(c)
+			if err != nil {
+				return err
+			}
+			v = loaded
+		}
+
+		if err := endpoint.bind(c, v); err != nil {
+			return err
+		}
+
+		if endpoint.Validate {
+			if err := c.Validate(v); err != nil {
+				if _, ok := err.(*echo.HTTPError); ok {
+					return err
+				}
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+			}
+		}
+
+		return endpoint.Handler(c, v)
+	}
+}
+
+// bind binds the request to v with protection.
+func (endpoint Endpoint[T]) bind(c echo.Context, v *T) error {
+	if reflect.TypeOf(v).Elem().Kind() == reflect.Slice {
+		option := endpoint.SliceOpt
+		if option == "" {
+			option = "overwrite"
+		}
+		return BindSlice(endpoint.Tag, c, v, option)
+	}
+	return Bind(endpoint.Tag, c, v)
+}
@@ -0,0 +1,125 @@
+package protectecho
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type nameValidator struct{}
+
+func (nameValidator) Validate(i interface{}) error {
+	if v, ok := i.(*TestStruct); ok && v.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestHandle(t *testing.T) {
+	t.Run("Bind with protection", func(t *testing.T) {
+		e := echo.New()
+		var got TestStruct
+		Handle(e, "PUT /items/:id", Endpoint[TestStruct]{
+			Tag: "update",
+			Load: func(c echo.Context) (*TestStruct, error) {
+				return &TestStruct{ID: c.Param("id"), Code: "existing", Name: "existing"}, nil
+			},
+			Handler: func(c echo.Context, v *TestStruct) error {
+				got = *v
+				return c.NoContent(http.StatusNoContent)
+			},
+		})
+
+		reqBody := `{"id":"123", "code":"ABC", "name":"Test"}`
+		req := httptest.NewRequest(http.MethodPut, "/items/item-1", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "item-1", got.ID)
+		assert.Equal(t, "existing", got.Code)
+		assert.Equal(t, "Test", got.Name)
+	})
+
+	t.Run("Slice option", func(t *testing.T) {
+		e := echo.New()
+		var got []TestStruct
+		Handle(e, "POST /items", Endpoint[[]TestStruct]{
+			Tag:      "create",
+			SliceOpt: "match",
+			Load: func(c echo.Context) (*[]TestStruct, error) {
+				return &[]TestStruct{{ID: "existing"}}, nil
+			},
+			Handler: func(c echo.Context, v *[]TestStruct) error {
+				got = *v
+				return c.NoContent(http.StatusNoContent)
+			},
+		})
+
+		reqBody := `[{"id":"123", "name":"Test1"},{"id":"456", "name":"Test2"}]`
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, 2, len(got))
+		assert.Equal(t, "existing", got[0].ID)
+		assert.Equal(t, "Test1", got[0].Name)
+		assert.Empty(t, got[1].ID)
+	})
+
+	t.Run("Validation error", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = nameValidator{}
+		called := false
+		Handle(e, "POST /items", Endpoint[TestStruct]{
+			Tag:      "create",
+			Validate: true,
+			Handler: func(c echo.Context, v *TestStruct) error {
+				called = true
+				return nil
+			},
+		})
+
+		reqBody := `{"id":"123", "code":"ABC"}`
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "name is required")
+		assert.False(t, called)
+	})
+
+	t.Run("Bind error", func(t *testing.T) {
+		e := echo.New()
+		Handle(e, "POST /items", Endpoint[TestStruct]{
+			Tag: "create",
+			Handler: func(c echo.Context, v *TestStruct) error {
+				return nil
+			},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"id":`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Invalid route", func(t *testing.T) {
+		e := echo.New()
+		assert.Panics(t, func() {
+			Handle(e, "/items", Endpoint[TestStruct]{})
+		})
+	})
+}
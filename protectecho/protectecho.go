@@ -8,12 +8,47 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// protectorContextKey is the key to store the request-scoped Protector in echo.Context.
+const protectorContextKey = "github.com/ikedam/protect/protectecho.protector"
+
+// SetProtector stores the Protector to use for the request in the echo.Context.
+// Bind and BindSlice use it instead of protect.DefaultProtector.
+func SetProtector(c echo.Context, p *protect.Protector) {
+	c.Set(protectorContextKey, p)
+}
+
+// GetProtector returns the Protector stored in the echo.Context with SetProtector.
+// It returns protect.DefaultProtector if no Protector is stored.
+func GetProtector(c echo.Context) *protect.Protector {
+	if p, ok := c.Get(protectorContextKey).(*protect.Protector); ok && p != nil {
+		return p
+	}
+	return protect.DefaultProtector
+}
+
+// WithProtector returns a middleware that stores the Protector returned by selector in the echo.Context.
+// This allows selecting a Protector per request (e.g. per tenant or per role)
+// without changing handler code.
+// If selector returns nil, the Protector is left unchanged.
+func WithProtector(selector func(c echo.Context) *protect.Protector) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if p := selector(c); p != nil {
+				SetProtector(c, p)
+			}
+			return next(c)
+		}
+	}
+}
+
 // Bind binds the request data to the provided destination struct
 // and applies the protection rules specified by the tag.
 // This is a wrapper around echo.Context.Bind() that adds protection.
 func Bind(tag string, c echo.Context, dst interface{}) error {
+	p := GetProtector(c)
+
 	// Create a clone of the destination
-	clone := protect.Clone(dst)
+	clone := p.Clone(dst)
 
 	// Bind the request data to the clone
 	if err := c.Bind(clone); err != nil {
@@ -21,7 +56,7 @@ func Bind(tag string, c echo.Context, dst interface{}) error {
 	}
 
 	// Apply protection rules
-	return protect.Copy(tag, clone, dst)
+	return p.Copy(tag, clone, dst)
 }
 
 // BindSlice binds the request data to the provided destination slice
@@ -34,9 +69,13 @@ func Bind(tag string, c echo.Context, dst interface{}) error {
 // - "match": Adjusts destination length to match source length
 // - "longer": Keeps destination if longer than source, otherwise extends it
 // - "shorter": Truncates to the shorter of the two slices
+// - "matchbykey=<field>": Pairs struct elements by the key field (e.g. "matchbykey=ID") instead of indices
+// - "set": Merges comparable elements (e.g. []string) as a set, keeping the order and dropping duplicates
 func BindSlice(tag string, c echo.Context, dst interface{}, option string) error {
+	p := GetProtector(c)
+
 	// Create a clone of the destination
-	clone := protect.Clone(dst)
+	clone := p.Clone(dst)
 
 	// Bind the request data to the clone
 	if err := c.Bind(clone); err != nil {
@@ -44,7 +83,46 @@ func BindSlice(tag string, c echo.Context, dst interface{}, option string) error
 	}
 
 	// Apply protection rules
-	return protect.CopySlice(tag, clone, dst, option)
+	return p.CopySlice(tag, clone, dst, option)
+}
+
+// headersBinder is implemented by binders supporting binding of request headers
+// such as echo.DefaultBinder.
+type headersBinder interface {
+	BindHeaders(c echo.Context, i interface{}) error
+}
+
+// BindHeaders binds the request headers to the provided destination struct
+// and applies the protection rules specified by the tag.
+// Fields are bound from headers with `header:"..."` tags as echo.DefaultBinder.BindHeaders() does.
+// Echo's Bind() never binds headers, so call this in addition to Bind when headers are needed.
+//
+// Fields that must never be supplied by clients through headers
+// (e.g. X-User-ID set by a trusted proxy) can be protected with a dedicated tag:
+//
+//	type Request struct {
+//		UserID string `header:"X-User-ID" protectfor:"public"`
+//	}
+//
+//	err := protectecho.BindHeaders("public", c, &req)
+func BindHeaders(tag string, c echo.Context, dst interface{}) error {
+	p := GetProtector(c)
+
+	binder, ok := c.Echo().Binder.(headersBinder)
+	if !ok {
+		binder = &echo.DefaultBinder{}
+	}
+
+	// Create a clone of the destination
+	clone := p.Clone(dst)
+
+	// Bind the request headers to the clone
+	if err := binder.BindHeaders(c, clone); err != nil {
+		return err
+	}
+
+	// Apply protection rules
+	return p.Copy(tag, clone, dst)
 }
 
 // rebindableContext is a wrapper around echo.Context that allows rebinding.
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ikedam/protect"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -56,6 +57,45 @@ func TestBind(t *testing.T) {
 		assert.Empty(t, dst.Code)
 		assert.Equal(t, "Test", dst.Name)
 	})
+
+	t.Run("Transitions with the zero value", func(t *testing.T) {
+		type Article struct {
+			ID     string `protectfor:"update" json:"id"`
+			Status string `json:"status"`
+		}
+		p := protect.NewProtector("protectfor", "protectopt")
+		p.RegisterTransitions(&Article{}, "Status", protect.Transitions{
+			"":          {"draft"},
+			"draft":     {"published"},
+			"published": {"archived"},
+		})
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"id":"2","status":"archived"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := e.NewContext(req, httptest.NewRecorder())
+		SetProtector(c, p)
+
+		dst := Article{ID: "1", Status: "published"}
+		assert.NoError(t, Bind("update", c, &dst))
+		assert.Equal(t, Article{ID: "1", Status: "archived"}, dst)
+	})
+
+	t.Run("Slice options of dst", func(t *testing.T) {
+		type Tagged struct {
+			Name string   `json:"name"`
+			Tags []string `json:"tags" protectopt:"shorter"`
+		}
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"name":"n"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		dst := Tagged{Tags: []string{"a", "b"}}
+		assert.NoError(t, Bind("update", c, &dst))
+		assert.Equal(t, Tagged{Name: "n", Tags: []string{"a", "b"}}, dst)
+	})
 }
 
 func TestReBindable(t *testing.T) {
@@ -282,3 +322,156 @@ func TestBindSlice(t *testing.T) {
 		assert.Equal(t, "existing", dst2[0].ID) // ID is preserved
 	})
 }
+
+type TenantStruct struct {
+	ID   string `protectfor:"create" json:"id"`
+	Code string `tenantprotect:"create" json:"code"`
+	Name string `json:"name"`
+}
+
+func TestWithProtector(t *testing.T) {
+	tenantProtector := protect.NewProtector("tenantprotect", "protectopt")
+
+	t.Run("Request-scoped protector", func(t *testing.T) {
+		e := echo.New()
+		reqBody := `{"id":"123", "code":"ABC", "name":"Test"}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		dst := TenantStruct{}
+		handler := WithProtector(func(c echo.Context) *protect.Protector {
+			return tenantProtector
+		})(func(c echo.Context) error {
+			return Bind("create", c, &dst)
+		})
+		err := handler(c)
+		assert.NoError(t, err)
+
+		// Protected by tenantprotect, not by protectfor
+		assert.Equal(t, "123", dst.ID)
+		assert.Empty(t, dst.Code)
+		assert.Equal(t, "Test", dst.Name)
+	})
+
+	t.Run("Default protector", func(t *testing.T) {
+		e := echo.New()
+		reqBody := `{"id":"123", "code":"ABC", "name":"Test"}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		dst := TenantStruct{}
+		handler := WithProtector(func(c echo.Context) *protect.Protector {
+			return nil
+		})(func(c echo.Context) error {
+			return Bind("create", c, &dst)
+		})
+		err := handler(c)
+		assert.NoError(t, err)
+
+		assert.Same(t, protect.DefaultProtector, GetProtector(c))
+		assert.Empty(t, dst.ID)
+		assert.Equal(t, "ABC", dst.Code)
+		assert.Equal(t, "Test", dst.Name)
+	})
+
+	t.Run("Survives ReBindable", func(t *testing.T) {
+		e := echo.New()
+		reqBody := `{"id":"123", "code":"ABC", "name":"Test"}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(reqBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		SetProtector(c, tenantProtector)
+		c = ReBindable(c)
+
+		dst := TenantStruct{}
+		err := Bind("create", c, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, "123", dst.ID)
+		assert.Empty(t, dst.Code)
+	})
+}
+
+type HeaderStruct struct {
+	UserID    string `header:"X-User-ID" protectfor:"public"`
+	RequestID string `header:"X-Request-ID"`
+	Name      string `json:"name"`
+}
+
+func TestBindHeaders(t *testing.T) {
+	newContext := func() echo.Context {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-User-ID", "attacker")
+		req.Header.Set("X-Request-ID", "req-1")
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec)
+	}
+
+	t.Run("Public route", func(t *testing.T) {
+		c := newContext()
+
+		dst := HeaderStruct{UserID: "trusted", Name: "existing"}
+		err := BindHeaders("public", c, &dst)
+		assert.NoError(t, err)
+
+		// UserID must not be injected from client headers
+		assert.Equal(t, "trusted", dst.UserID)
+		assert.Equal(t, "req-1", dst.RequestID)
+		assert.Equal(t, "existing", dst.Name)
+	})
+
+	t.Run("Internal route", func(t *testing.T) {
+		c := newContext()
+
+		dst := HeaderStruct{}
+		err := BindHeaders("internal", c, &dst)
+		assert.NoError(t, err)
+
+		assert.Equal(t, "attacker", dst.UserID)
+		assert.Equal(t, "req-1", dst.RequestID)
+	})
+}
+
+// countingSerializer is a custom echo.JSONSerializer counting Deserialize calls.
+type countingSerializer struct {
+	echo.DefaultJSONSerializer
+	deserialized int
+}
+
+func (s *countingSerializer) Deserialize(c echo.Context, i interface{}) error {
+	s.deserialized++
+	return s.DefaultJSONSerializer.Deserialize(c, i)
+}
+
+func TestCustomJSONSerializer(t *testing.T) {
+	e := echo.New()
+	serializer := &countingSerializer{}
+	e.JSONSerializer = serializer
+
+	reqBody := `{"id":"123", "code":"ABC", "name":"Test"}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(reqBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := ReBindable(e.NewContext(req, rec))
+
+	dst1 := TestStruct{}
+	err := Bind("create", c, &dst1)
+	assert.NoError(t, err)
+	assert.Empty(t, dst1.ID)
+	assert.Equal(t, "ABC", dst1.Code)
+
+	// The custom serializer receives the whole body on every rebind
+	dst2 := TestStruct{}
+	err = Bind("update", c, &dst2)
+	assert.NoError(t, err)
+	assert.Empty(t, dst2.Code)
+	assert.Equal(t, "Test", dst2.Name)
+
+	assert.Equal(t, 2, serializer.deserialized)
+}
@@ -0,0 +1,112 @@
+package protectecho
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ikedam/protect"
+	"github.com/labstack/echo/v4"
+)
+
+// routeDeclaration declares how a route binds requests.
+type routeDeclaration struct {
+	// tag is the tag to protect fields. Empty for routes exempted with AllowUnprotected.
+	tag string
+	// bindType is the type requests are bound to. Nil for routes exempted with AllowUnprotected.
+	bindType reflect.Type
+	// exempt is true for routes exempted with AllowUnprotected.
+	exempt bool
+}
+
+// routeRegistry holds declarations of routes of an echo.Echo.
+type routeRegistry struct {
+	mu     sync.Mutex
+	routes map[string]routeDeclaration
+}
+
+// registries holds routeRegistry by *echo.Echo.
+var registries sync.Map
+
+// registerRoute records the declaration of the route of e.
+func registerRoute(e *echo.Echo, method, path string, declaration routeDeclaration) {
+	v, _ := registries.LoadOrStore(e, &routeRegistry{routes: make(map[string]routeDeclaration)})
+	registry := v.(*routeRegistry)
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.routes[method+" "+path] = declaration
+}
+
+// declarations returns the route declarations of e.
+func declarations(e *echo.Echo) map[string]routeDeclaration {
+	v, ok := registries.Load(e)
+	if !ok {
+		return nil
+	}
+	registry := v.(*routeRegistry)
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	routes := make(map[string]routeDeclaration, len(registry.routes))
+	for k, v := range registry.routes {
+		routes[k] = v
+	}
+	return routes
+}
+
+// AllowUnprotected exempts the route from CheckRoutes,
+// e.g. for webhooks verified by signatures or endpoints without request bodies.
+// route is a method and a path separated by a space, like "POST /webhooks/payment".
+func AllowUnprotected(e *echo.Echo, route string) {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	if !ok {
+		panic(fmt.Sprintf("protectecho: invalid route %q: must be \"METHOD /path\"", route))
+	}
+	registerRoute(e, strings.ToUpper(method), strings.TrimSpace(path), routeDeclaration{exempt: true})
+}
+
+// ErrUnprotectedRoute is wrapped by the error of CheckRoutes.
+var ErrUnprotectedRoute = errors.New("unprotected route")
+
+// CheckRoutes checks that every route of e mutating state (POST, PUT and PATCH)
+// is registered with Handle with a tag protecting at least one field of its bind type,
+// or exempted with AllowUnprotected.
+// Call this at startup after registering all routes and fail to boot on errors,
+// as a guardrail against handlers forgetting protection.
+// Fields are checked with protect.DefaultProtector.
+// The error wraps ErrUnprotectedRoute and lists all offending routes.
+func CheckRoutes(e *echo.Echo) error {
+	declared := declarations(e)
+
+	var problems []string
+	for _, route := range e.Routes() {
+		switch route.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			continue
+		}
+
+		key := route.Method + " " + route.Path
+		declaration, ok := declared[key]
+		switch {
+		case !ok:
+			problems = append(problems, key+": not registered with protectecho.Handle")
+		case declaration.exempt:
+		case declaration.tag == "":
+			problems = append(problems, key+": no protection tag")
+		case len(protect.ProtectedFields(declaration.tag, reflect.New(declaration.bindType).Interface())) == 0:
+			problems = append(problems, fmt.Sprintf("%s: no fields of %s are protected for %q", key, declaration.bindType, declaration.tag))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return fmt.Errorf("%w:\n%s", ErrUnprotectedRoute, strings.Join(problems, "\n"))
+}
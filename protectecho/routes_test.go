@@ -0,0 +1,54 @@
+package protectecho
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRoutes(t *testing.T) {
+	handler := func(c echo.Context, v *TestStruct) error {
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	t.Run("All mutating routes protected", func(t *testing.T) {
+		e := echo.New()
+		Handle(e, "POST /items", Endpoint[TestStruct]{Tag: "create", Handler: handler})
+		Handle(e, "PUT /items/:id", Endpoint[TestStruct]{Tag: "update", Handler: handler})
+		Handle(e, "PATCH /items", Endpoint[[]TestStruct]{Tag: "update", Handler: func(c echo.Context, v *[]TestStruct) error {
+			return c.NoContent(http.StatusNoContent)
+		}})
+		e.GET("/items/:id", func(c echo.Context) error { return nil })
+		e.DELETE("/items/:id", func(c echo.Context) error { return nil })
+		e.POST("/webhooks", func(c echo.Context) error { return nil })
+		AllowUnprotected(e, "post /webhooks")
+
+		assert.NoError(t, CheckRoutes(e))
+	})
+
+	t.Run("Unprotected routes", func(t *testing.T) {
+		e := echo.New()
+		Handle(e, "POST /items", Endpoint[TestStruct]{Handler: handler})
+		Handle(e, "PUT /items/:id", Endpoint[TestStruct]{Tag: "updat", Handler: handler})
+		e.PATCH("/items/:id", func(c echo.Context) error { return nil })
+
+		err := CheckRoutes(e)
+		require.ErrorIs(t, err, ErrUnprotectedRoute)
+		assert.Contains(t, err.Error(), `POST /items: no protection tag`)
+		assert.Contains(t, err.Error(), `PUT /items/:id: no fields of protectecho.TestStruct are protected for "updat"`)
+		assert.Contains(t, err.Error(), `PATCH /items/:id: not registered with protectecho.Handle`)
+	})
+
+	t.Run("Registries are separated by Echo", func(t *testing.T) {
+		e1 := echo.New()
+		Handle(e1, "POST /items", Endpoint[TestStruct]{Tag: "create", Handler: handler})
+		e2 := echo.New()
+		e2.POST("/items", func(c echo.Context) error { return nil })
+
+		assert.NoError(t, CheckRoutes(e1))
+		assert.ErrorIs(t, CheckRoutes(e2), ErrUnprotectedRoute)
+	})
+}
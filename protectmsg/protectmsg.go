@@ -0,0 +1,67 @@
+// Package protectmsg provides message handler wrappers applying protection rules
+// to payloads of message queues such as Kafka or NATS, as protectecho does for HTTP requests.
+package protectmsg
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ikedam/protect"
+)
+
+// ErrInvalidPayload is wrapped by errors for payloads which cannot be decoded.
+// Such messages fail every time, so consumers should not retry them
+// but send them to a dead letter queue instead.
+var ErrInvalidPayload = errors.New("invalid payload")
+
+// Handler declares how a JSON payload is decoded into T and handled.
+type Handler[T any] struct {
+	// Tag is the tag to protect fields when decoding payloads.
+	// Keys for protected fields are skipped, so they are left zero.
+	Tag string
+	// Protector is the Protector to decode payloads with. protect.DefaultProtector is used if nil.
+	Protector *protect.Protector
+	// DisallowProtectedFields rejects payloads containing protected fields instead of skipping them.
+	DisallowProtectedFields bool
+	// DisallowUnknownFields rejects payloads containing unknown fields.
+	DisallowUnknownFields bool
+	// Handle handles the decoded value.
+	Handle func(ctx context.Context, v *T) error
+}
+
+// Func returns the function to pass to the consumer of the message queue,
+// e.g. called with the value of a Kafka message or the data of a NATS message.
+// Decoding errors wrap ErrInvalidPayload, and errors of Handler.Handle are returned as they are.
+func (h Handler[T]) Func() func(ctx context.Context, payload []byte) error {
+	return func(ctx context.Context, payload []byte) error {
+		v, err := h.decode(payload)
+		if err != nil {
+			return err
+		}
+		return h.Handle(ctx, v)
+	}
+}
+
+// decode decodes the payload into a new T with protection.
+func (h Handler[T]) decode(payload []byte) (*T, error) {
+	p := h.Protector
+	if p == nil {
+		p = protect.DefaultProtector
+	}
+
+	dec := p.NewDecoder(h.Tag, bytes.NewReader(payload))
+	if h.DisallowProtectedFields {
+		dec.DisallowProtectedFields()
+	}
+	if h.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	v := new(T)
+	if err := dec.Decode(v); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+	}
+	return v, nil
+}
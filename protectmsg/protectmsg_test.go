@@ -0,0 +1,71 @@
+package protectmsg
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ikedam/protect"
+	"github.com/stretchr/testify/assert"
+)
+
+type Event struct {
+	ID      string `protectfor:"ingest" json:"id"`
+	Payload string `json:"payload"`
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("protected fields are skipped", func(t *testing.T) {
+		var got *Event
+		handle := Handler[Event]{
+			Tag: "ingest",
+			Handle: func(ctx context.Context, v *Event) error {
+				got = v
+				return nil
+			},
+		}.Func()
+
+		err := handle(context.Background(), []byte(`{"id":"forged","payload":"data"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, &Event{Payload: "data"}, got)
+	})
+
+	t.Run("invalid payloads", func(t *testing.T) {
+		called := false
+		h := Handler[Event]{
+			Tag: "ingest",
+			Handle: func(ctx context.Context, v *Event) error {
+				called = true
+				return nil
+			},
+		}
+
+		err := h.Func()(context.Background(), []byte(`{"id":`))
+		assert.ErrorIs(t, err, ErrInvalidPayload)
+
+		h.DisallowProtectedFields = true
+		err = h.Func()(context.Background(), []byte(`{"id":"forged"}`))
+		assert.ErrorIs(t, err, ErrInvalidPayload)
+		assert.ErrorIs(t, err, protect.ErrProtectedField)
+
+		h.DisallowUnknownFields = true
+		err = h.Func()(context.Background(), []byte(`{"unknown":1}`))
+		assert.ErrorIs(t, err, protect.ErrUnknownField)
+
+		assert.False(t, called)
+	})
+
+	t.Run("handler errors", func(t *testing.T) {
+		handlerErr := errors.New("handler failed")
+		handle := Handler[Event]{
+			Protector: protect.NewProtector("protectfor", "protectopt"),
+			Handle: func(ctx context.Context, v *Event) error {
+				return handlerErr
+			},
+		}.Func()
+
+		err := handle(context.Background(), []byte(`{}`))
+		assert.ErrorIs(t, err, handlerErr)
+		assert.NotErrorIs(t, err, ErrInvalidPayload)
+	})
+}
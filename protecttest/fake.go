@@ -0,0 +1,98 @@
+package protecttest
+
+import (
+	"sync"
+
+	"github.com/ikedam/protect"
+)
+
+// Call is a call recorded by FakeProtector.
+type Call struct {
+	// Method is the name of the called method.
+	Method string
+	// Tag is the tag passed to the method. Empty for Clone.
+	Tag string
+	// Src is the src passed to the method.
+	Src interface{}
+	// Dst is the dst passed to the method. Nil for Clone.
+	Dst interface{}
+	// Option is the option passed to CopySlice and CopyMap. Empty for Copy and Clone.
+	Option string
+	// Opts are the options passed to the method.
+	Opts []protect.Option
+}
+
+// FakeProtector is a protect.Copier recording calls without copying anything.
+// It is safe for concurrent use.
+type FakeProtector struct {
+	// Err is returned from Copy, CopySlice and CopyMap if set.
+	Err error
+	// CloneFunc is called by Clone if set. Clone returns src as is otherwise.
+	CloneFunc func(src interface{}) interface{}
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ protect.Copier = (*FakeProtector)(nil)
+
+// Copy records the call and returns Err.
+func (f *FakeProtector) Copy(tag string, src, dst interface{}, opts ...protect.Option) error {
+	f.record(Call{Method: "Copy", Tag: tag, Src: src, Dst: dst, Opts: opts})
+	return f.Err
+}
+
+// Clone records the call and returns the result of CloneFunc, or src if CloneFunc is nil.
+func (f *FakeProtector) Clone(src interface{}) interface{} {
+	f.record(Call{Method: "Clone", Src: src})
+	if f.CloneFunc != nil {
+		return f.CloneFunc(src)
+	}
+	return src
+}
+
+// CopySlice records the call and returns Err.
+func (f *FakeProtector) CopySlice(tag string, src, dst interface{}, option string, opts ...protect.Option) error {
+	f.record(Call{Method: "CopySlice", Tag: tag, Src: src, Dst: dst, Option: option, Opts: opts})
+	return f.Err
+}
+
+// CopyMap records the call and returns Err.
+func (f *FakeProtector) CopyMap(tag string, src, dst interface{}, option string, opts ...protect.Option) error {
+	f.record(Call{Method: "CopyMap", Tag: tag, Src: src, Dst: dst, Option: option, Opts: opts})
+	return f.Err
+}
+
+// Calls returns the recorded calls in the order they were made.
+func (f *FakeProtector) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Tags returns the tags passed to Copy, CopySlice and CopyMap in the order they were made.
+func (f *FakeProtector) Tags() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var tags []string
+	for _, call := range f.calls {
+		if call.Method != "Clone" {
+			tags = append(tags, call.Tag)
+		}
+	}
+	return tags
+}
+
+// Reset clears the recorded calls.
+func (f *FakeProtector) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+// record appends the call.
+func (f *FakeProtector) record(call Call) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+}
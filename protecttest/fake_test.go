@@ -0,0 +1,70 @@
+package protecttest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ikedam/protect"
+	"github.com/stretchr/testify/assert"
+)
+
+// updateName is a code under test taking a protect.Copier.
+func updateName(c protect.Copier, src, dst *Model) error {
+	clone := c.Clone(dst).(*Model)
+	return c.Copy("update", src, clone)
+}
+
+func TestFakeProtector(t *testing.T) {
+	t.Run("records calls", func(t *testing.T) {
+		f := &FakeProtector{}
+		src := &Model{Name: "src"}
+		dst := &Model{Name: "dst"}
+
+		err := updateName(f, src, dst)
+		assert.NoError(t, err)
+
+		err = f.CopySlice("create", &[]Model{}, &[]Model{}, "match")
+		assert.NoError(t, err)
+
+		err = f.CopyMap("create", &map[string]Model{}, &map[string]Model{}, "patch")
+		assert.NoError(t, err)
+
+		calls := f.Calls()
+		assert.Len(t, calls, 4)
+		assert.Equal(t, "Clone", calls[0].Method)
+		assert.Same(t, dst, calls[0].Src)
+		assert.Equal(t, "Copy", calls[1].Method)
+		assert.Same(t, src, calls[1].Src)
+		assert.Equal(t, "CopySlice", calls[2].Method)
+		assert.Equal(t, "match", calls[2].Option)
+		assert.Equal(t, "CopyMap", calls[3].Method)
+		assert.Equal(t, "patch", calls[3].Option)
+		assert.Equal(t, []string{"update", "create", "create"}, f.Tags())
+
+		// Nothing is copied
+		assert.Equal(t, "dst", dst.Name)
+
+		f.Reset()
+		assert.Empty(t, f.Calls())
+	})
+
+	t.Run("returns configured values", func(t *testing.T) {
+		errCopy := errors.New("copy failed")
+		cloned := &Model{Name: "cloned"}
+		f := &FakeProtector{
+			Err: errCopy,
+			CloneFunc: func(src interface{}) interface{} {
+				return cloned
+			},
+		}
+
+		err := updateName(f, &Model{}, &Model{})
+		assert.ErrorIs(t, err, errCopy)
+		assert.Same(t, cloned, f.Calls()[1].Dst)
+	})
+
+	t.Run("real protector", func(t *testing.T) {
+		err := updateName(protect.DefaultProtector, &Model{}, &Model{})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,75 @@
+package protecttest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ikedam/protect"
+)
+
+// UpdateGoldenEnv is the environment variable to update golden files instead of comparing them.
+// Run tests with PROTECTTEST_UPDATE=1 to accept the current protection rules.
+const UpdateGoldenEnv = "PROTECTTEST_UPDATE"
+
+// GoldenDir is the directory to store golden files.
+var GoldenDir = filepath.Join("testdata", "protect")
+
+// AssertGolden asserts that the protected fields of the type of sample for each tag
+// match the golden files in GoldenDir.
+// The golden file for a tag is named "<type>.<tag>.golden" and lists the paths
+// returned by protect.ProtectedFields, one per line.
+// When UpdateGoldenEnv is set, golden files are written instead of compared.
+func AssertGolden(t TestingT, sample interface{}, tags ...string) bool {
+	t.Helper()
+
+	typ := reflect.TypeOf(sample)
+	if typ == nil {
+		t.Errorf("sample must not be nil")
+		return false
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	update := os.Getenv(UpdateGoldenEnv) != ""
+
+	result := true
+	for _, tag := range tags {
+		path := filepath.Join(GoldenDir, typ.String()+"."+tag+".golden")
+
+		var b strings.Builder
+		for _, field := range protect.ProtectedFields(tag, sample) {
+			b.WriteString(field)
+			b.WriteString("\n")
+		}
+		actual := b.String()
+
+		if update {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Errorf("failed to create directory for %s: %v", path, err)
+				result = false
+				continue
+			}
+			if err := os.WriteFile(path, []byte(actual), 0o644); err != nil {
+				t.Errorf("failed to write %s: %v", path, err)
+				result = false
+			}
+			continue
+		}
+
+		expected, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("failed to read %s (set %s=1 to create it): %v", path, UpdateGoldenEnv, err)
+			result = false
+			continue
+		}
+		if string(expected) != actual {
+			t.Errorf("protected fields of %s for tag %q changed (set %s=1 to accept):\nexpected:\n%sactual:\n%s", typ, tag, UpdateGoldenEnv, expected, actual)
+			result = false
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,54 @@
+package protecttest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertGolden(t *testing.T) {
+	t.Run("matches golden", func(t *testing.T) {
+		assert.True(t, AssertGolden(t, &Model{}, "create", "update"))
+	})
+
+	t.Run("rules changed", func(t *testing.T) {
+		dir := t.TempDir()
+		orig := GoldenDir
+		GoldenDir = dir
+		defer func() { GoldenDir = orig }()
+
+		err := os.WriteFile(filepath.Join(dir, "protecttest.Model.update.golden"), []byte("ID\n"), 0o644)
+		assert.NoError(t, err)
+
+		r := &recorder{}
+		assert.False(t, AssertGolden(r, &Model{}, "update"))
+		assert.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "changed")
+	})
+
+	t.Run("update golden", func(t *testing.T) {
+		dir := t.TempDir()
+		orig := GoldenDir
+		GoldenDir = dir
+		defer func() { GoldenDir = orig }()
+		t.Setenv(UpdateGoldenEnv, "1")
+
+		assert.True(t, AssertGolden(t, &Model{}, "update"))
+
+		b, err := os.ReadFile(filepath.Join(dir, "protecttest.Model.update.golden"))
+		assert.NoError(t, err)
+		assert.Equal(t, "ID\nCreatedAt\nChild.ID\n", string(b))
+	})
+
+	t.Run("missing golden", func(t *testing.T) {
+		orig := GoldenDir
+		GoldenDir = t.TempDir()
+		defer func() { GoldenDir = orig }()
+
+		r := &recorder{}
+		assert.False(t, AssertGolden(r, &Model{}, "update"))
+		assert.Contains(t, r.errors[0], UpdateGoldenEnv)
+	})
+}
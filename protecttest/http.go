@@ -0,0 +1,94 @@
+package protecttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+
+	"github.com/ikedam/protect"
+	"github.com/labstack/echo/v4"
+)
+
+// AssertHandlerProtected asserts that a client cannot alter fields protected for the tag
+// through the echo handler, e.g. "can a client overwrite ID?".
+// sample is sent to the handler as the JSON body of a request,
+// and the handler must respond with the bound value as JSON, like most create and update handlers do.
+// Each field protected for the tag and non-zero in sample must differ from sample in the response,
+// so give values in sample the server never sets by itself.
+// Fields in elements of slices, arrays and maps (e.g. "Items[].ID") are not verified;
+// use AssertProtected for them.
+func AssertHandlerProtected[T any](t TestingT, tag string, handler echo.HandlerFunc, sample *T) bool {
+	t.Helper()
+
+	body, err := json.Marshal(sample)
+	if err != nil {
+		t.Errorf("failed to encode sample: %v", err)
+		return false
+	}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if err := handler(c); err != nil {
+		t.Errorf("handler failed: %v", err)
+		return false
+	}
+	if rec.Code < 200 || rec.Code >= 300 {
+		t.Errorf("handler responded with status %d: %s", rec.Code, rec.Body.String())
+		return false
+	}
+
+	result := new(T)
+	if err := json.Unmarshal(rec.Body.Bytes(), result); err != nil {
+		t.Errorf("failed to decode the response as %T: %v", result, err)
+		return false
+	}
+
+	verified := 0
+	ok := true
+	for _, field := range protect.ProtectedFields(tag, sample) {
+		if strings.Contains(field, "[]") {
+			continue
+		}
+		sent, err := fieldByPath(reflect.ValueOf(sample), field)
+		if err != nil || sent.IsZero() {
+			continue
+		}
+		verified++
+
+		got, err := fieldByPath(reflect.ValueOf(result), field)
+		if err != nil {
+			// The path is nil in the response, so the field is not altered
+			continue
+		}
+		if sameJSON(sent.Interface(), got.Interface()) {
+			t.Errorf("field %s is not protected for tag %q: the client altered it to %v", field, tag, got.Interface())
+			ok = false
+		}
+	}
+
+	if verified == 0 {
+		t.Errorf("sample has no non-zero fields protected for tag %q to verify", tag)
+		return false
+	}
+	return ok
+}
+
+// sameJSON checks if a and b are encoded into the same JSON,
+// ignoring differences lost in the request, like monotonic clock readings of time.Time.
+func sameJSON(a, b interface{}) bool {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(ja, jb)
+}
@@ -0,0 +1,66 @@
+package protecttest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ikedam/protect/protectecho"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// updateHandler binds the request onto the existing model with the tag.
+func updateHandler(tag string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		existing := &Model{ID: "existing", Name: "Existing", Child: &Child{ID: "existing-child"}}
+		if err := protectecho.Bind(tag, c, existing); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, existing)
+	}
+}
+
+func TestAssertHandlerProtected(t *testing.T) {
+	t.Run("protected handler", func(t *testing.T) {
+		assert.True(t, AssertHandlerProtected(t, "update", updateHandler("update"), newSample()))
+	})
+
+	t.Run("handler binding with a wrong tag", func(t *testing.T) {
+		r := &recorder{}
+		assert.False(t, AssertHandlerProtected(r, "update", updateHandler("create"), newSample()))
+		assert.Len(t, r.errors, 2)
+		assert.Contains(t, r.errors[0], "CreatedAt is not protected")
+		assert.Contains(t, r.errors[1], "Child.ID is not protected")
+	})
+
+	t.Run("handler binding without protection", func(t *testing.T) {
+		r := &recorder{}
+		handler := func(c echo.Context) error {
+			var m Model
+			if err := c.Bind(&m); err != nil {
+				return err
+			}
+			return c.JSON(http.StatusOK, &m)
+		}
+		assert.False(t, AssertHandlerProtected(r, "update", handler, newSample()))
+		assert.Len(t, r.errors, 3)
+		assert.Contains(t, r.errors[0], "ID is not protected")
+	})
+
+	t.Run("failing handler", func(t *testing.T) {
+		r := &recorder{}
+		handler := func(c echo.Context) error {
+			return c.String(http.StatusBadRequest, "bad request")
+		}
+		assert.False(t, AssertHandlerProtected(r, "update", handler, newSample()))
+		assert.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "status 400")
+	})
+
+	t.Run("nothing to verify", func(t *testing.T) {
+		r := &recorder{}
+		assert.False(t, AssertHandlerProtected(r, "update", updateHandler("update"), &Model{Name: "Test"}))
+		assert.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "no non-zero fields")
+	})
+}
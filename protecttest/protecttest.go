@@ -0,0 +1,123 @@
+// Package protecttest provides helpers to test protection rules of models.
+package protecttest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/ikedam/protect"
+)
+
+// TestingT is the subset of testing.TB used by the helpers.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertProtected asserts that the specified fields of sample are protected for the tag.
+// sample is copied into a zero value of the same type with protect.Copy,
+// and each field must be left zero in the result.
+// Fields are specified by names, and nested fields are separated by dots (e.g. "Parent.ID").
+// Each field must be non-zero in sample, otherwise the protection cannot be verified.
+func AssertProtected(t TestingT, sample interface{}, tag string, fields ...string) bool {
+	t.Helper()
+
+	dst, ok := copyToZero(t, tag, sample)
+	if !ok {
+		return false
+	}
+
+	result := true
+	for _, field := range fields {
+		srcField, err := fieldByPath(reflect.ValueOf(sample), field)
+		if err != nil {
+			t.Errorf("field %s of sample: %v", field, err)
+			result = false
+			continue
+		}
+		if srcField.IsZero() {
+			t.Errorf("field %s of sample must not be zero to verify protection", field)
+			result = false
+			continue
+		}
+
+		dstField, err := fieldByPath(dst, field)
+		if err != nil {
+			// The path is nil in the result, so the field is not copied
+			continue
+		}
+		if !dstField.IsZero() {
+			t.Errorf("field %s is not protected for tag %q: copied %v", field, tag, dstField.Interface())
+			result = false
+		}
+	}
+
+	return result
+}
+
+// RoundTrip copies src into a zero value of the same type with protect.Copy
+// and returns a pointer to the result.
+// It reports an error if the copy fails or src is modified by the copy.
+func RoundTrip(t TestingT, tag string, src interface{}) interface{} {
+	t.Helper()
+
+	before := protect.Clone(src)
+
+	dst, ok := copyToZero(t, tag, src)
+	if !ok {
+		return nil
+	}
+
+	if !reflect.DeepEqual(before, src) {
+		t.Errorf("src is modified by Copy with tag %q", tag)
+	}
+
+	return dst.Addr().Interface()
+}
+
+// copyToZero copies src into a new zero value of the same type and returns it.
+func copyToZero(t TestingT, tag string, src interface{}) (reflect.Value, bool) {
+	t.Helper()
+
+	srcVal := reflect.ValueOf(src)
+	if !srcVal.IsValid() {
+		t.Errorf("src must not be nil")
+		return reflect.Value{}, false
+	}
+	if srcVal.Kind() == reflect.Ptr {
+		if srcVal.IsNil() {
+			t.Errorf("src must not be nil pointer")
+			return reflect.Value{}, false
+		}
+		srcVal = srcVal.Elem()
+	}
+
+	dst := reflect.New(srcVal.Type())
+	if err := protect.Copy(tag, srcVal.Interface(), dst.Interface()); err != nil {
+		t.Errorf("Copy with tag %q failed: %v", tag, err)
+		return reflect.Value{}, false
+	}
+
+	return dst.Elem(), true
+}
+
+// fieldByPath returns the field specified by the dot-separated path.
+func fieldByPath(v reflect.Value, path string) (reflect.Value, error) {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}, errors.New("nil in the path")
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, errors.New("not a struct field")
+		}
+		v = v.FieldByName(name)
+		if !v.IsValid() {
+			return reflect.Value{}, errors.New("no such field")
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,87 @@
+package protecttest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Child struct {
+	ID   string `protectfor:"update"`
+	Name string
+}
+
+type Model struct {
+	ID        string    `protectfor:"create,update"`
+	CreatedAt time.Time `protectfor:"update"`
+	Name      string
+	Child     *Child
+}
+
+// recorder is a TestingT recording reported errors.
+type recorder struct {
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func newSample() *Model {
+	return &Model{
+		ID:        "123",
+		CreatedAt: time.Now(),
+		Name:      "Test",
+		Child:     &Child{ID: "child", Name: "Child"},
+	}
+}
+
+func TestAssertProtected(t *testing.T) {
+	t.Run("protected fields", func(t *testing.T) {
+		assert.True(t, AssertProtected(t, newSample(), "update", "ID", "CreatedAt", "Child.ID"))
+	})
+
+	t.Run("unprotected field", func(t *testing.T) {
+		r := &recorder{}
+		assert.False(t, AssertProtected(r, newSample(), "create", "ID", "CreatedAt"))
+		assert.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "CreatedAt is not protected")
+	})
+
+	t.Run("zero field in sample", func(t *testing.T) {
+		r := &recorder{}
+		sample := newSample()
+		sample.ID = ""
+		assert.False(t, AssertProtected(r, sample, "update", "ID"))
+		assert.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "must not be zero")
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		r := &recorder{}
+		assert.False(t, AssertProtected(r, newSample(), "update", "Unknown"))
+		assert.Contains(t, r.errors[0], "no such field")
+	})
+}
+
+func TestRoundTrip(t *testing.T) {
+	src := newSample()
+
+	result := RoundTrip(t, "update", src).(*Model)
+	assert.Empty(t, result.ID)
+	assert.True(t, result.CreatedAt.IsZero())
+	assert.Equal(t, "Test", result.Name)
+	assert.NotSame(t, src.Child, result.Child)
+	assert.Empty(t, result.Child.ID)
+	assert.Equal(t, "Child", result.Child.Name)
+
+	t.Run("copy error", func(t *testing.T) {
+		r := &recorder{}
+		assert.Nil(t, RoundTrip(r, "update", nil))
+		assert.Len(t, r.errors, 1)
+	})
+}
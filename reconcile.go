@@ -0,0 +1,93 @@
+package protect
+
+import (
+	"reflect"
+)
+
+// Reconciliation is the set of actions to bring the actual state to the desired state, computed by Reconcile.
+type Reconciliation[K comparable, V any] struct {
+	// Create holds desired values whose keys are not in the actual state.
+	Create map[K]V
+	// Update holds values merged from the desired state onto the actual state
+	// for keys in both states whose values change.
+	// Protected fields keep the actual values.
+	Update map[K]V
+	// Delete holds actual values whose keys are not in the desired state.
+	Delete map[K]V
+}
+
+// Reconcile computes the actions to bring actual to desired with DefaultProtector.
+// See ReconcileWith for details.
+func Reconcile[K comparable, V any](tag string, desired, actual map[K]V, opts ...Option) (*Reconciliation[K, V], error) {
+	return ReconcileWith(DefaultProtector, tag, desired, actual, opts...)
+}
+
+// ReconcileWith computes the actions to bring actual to desired with p.
+// Values for keys in both maps are merged as Copy does with the tag and opts,
+// so changes only in protected fields result in no updates.
+// Neither desired nor actual is modified; values in the result are deep copies.
+// Note that fields stamped for the tag with `protectstamp` tags make every merged value differ,
+// so use a tag without stamps to detect changes.
+func ReconcileWith[K comparable, V any](p *Protector, tag string, desired, actual map[K]V, opts ...Option) (*Reconciliation[K, V], error) {
+	r := &Reconciliation[K, V]{
+		Create: make(map[K]V),
+		Update: make(map[K]V),
+		Delete: make(map[K]V),
+	}
+
+	for k, d := range desired {
+		a, ok := actual[k]
+		if !ok {
+			clone, err := cloneT(p, d)
+			if err != nil {
+				return nil, err
+			}
+			r.Create[k] = clone
+			continue
+		}
+
+		merged, err := cloneT(p, a)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Copy(tag, &d, &merged, opts...); err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(merged, a) {
+			r.Update[k] = merged
+		}
+	}
+
+	for k, a := range actual {
+		if _, ok := desired[k]; !ok {
+			clone, err := cloneT(p, a)
+			if err != nil {
+				return nil, err
+			}
+			r.Delete[k] = clone
+		}
+	}
+
+	return r, nil
+}
+
+// ReconcileSlice computes the actions to bring actual to desired as Reconcile does,
+// identifying elements by key.
+// If multiple elements have the same key, the last one is used.
+func ReconcileSlice[K comparable, V any](tag string, desired, actual []V, key func(v V) K, opts ...Option) (*Reconciliation[K, V], error) {
+	return ReconcileSliceWith(DefaultProtector, tag, desired, actual, key, opts...)
+}
+
+// ReconcileSliceWith computes the actions to bring actual to desired with p as ReconcileSlice does.
+func ReconcileSliceWith[K comparable, V any](p *Protector, tag string, desired, actual []V, key func(v V) K, opts ...Option) (*Reconciliation[K, V], error) {
+	return ReconcileWith(p, tag, sliceToMap(desired, key), sliceToMap(actual, key), opts...)
+}
+
+// sliceToMap indexes values by key.
+func sliceToMap[K comparable, V any](values []V, key func(v V) K) map[K]V {
+	m := make(map[K]V, len(values))
+	for _, v := range values {
+		m[key(v)] = v
+	}
+	return m
+}
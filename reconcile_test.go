@@ -0,0 +1,66 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconcile(t *testing.T) {
+	desired := map[string]SimpleStruct{
+		"new":       {ID: "new", Name: "New"},
+		"changed":   {ID: "changed", Name: "Changed"},
+		"unchanged": {ID: "unchanged", Name: "Same"},
+		"protected": {ID: "forged", Name: "Same"},
+	}
+	actual := map[string]SimpleStruct{
+		"changed":   {ID: "changed", Name: "Old"},
+		"unchanged": {ID: "unchanged", Name: "Same"},
+		"protected": {ID: "protected", Name: "Same"},
+		"gone":      {ID: "gone", Name: "Gone"},
+	}
+
+	r, err := Reconcile("update", desired, actual)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]SimpleStruct{"new": {ID: "new", Name: "New"}}, r.Create)
+	assert.Equal(t, map[string]SimpleStruct{"changed": {ID: "changed", Name: "Changed"}}, r.Update)
+	assert.Equal(t, map[string]SimpleStruct{"gone": {ID: "gone", Name: "Gone"}}, r.Delete)
+
+	// Inputs are not modified
+	assert.Equal(t, "Old", actual["changed"].Name)
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Reconcile("update", desired, actual, WithBudget(1))
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+	})
+
+	t.Run("with a Protector", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		p.SetAllowlistTag("copyfor")
+		r, err := ReconcileWith(p, "update", desired, actual)
+		assert.NoError(t, err)
+		// Fields are not copied without copyfor tags in the allowlist mode
+		assert.Empty(t, r.Update)
+		assert.Len(t, r.Create, 1)
+		assert.Len(t, r.Delete, 1)
+	})
+}
+
+func TestReconcileSlice(t *testing.T) {
+	desired := []*SliceHolder{{Items: []SimpleStruct{{ID: "1", Name: "New"}}}}
+	actual := []*SliceHolder{
+		{Items: []SimpleStruct{{ID: "1", Name: "Old"}}},
+		{Items: []SimpleStruct{{ID: "2", Name: "Old"}}},
+	}
+	key := func(v *SliceHolder) string { return v.Items[0].ID }
+
+	r, err := ReconcileSlice("update", desired, actual, key, WithSliceOption("Items", "match"))
+	assert.NoError(t, err)
+	assert.Empty(t, r.Create)
+	assert.Equal(t, map[string]*SliceHolder{"1": {Items: []SimpleStruct{{ID: "1", Name: "New"}}}}, r.Update)
+	assert.Equal(t, map[string]*SliceHolder{"2": {Items: []SimpleStruct{{ID: "2", Name: "Old"}}}}, r.Delete)
+
+	// Results are deep copies
+	assert.NotSame(t, actual[1], r.Delete["2"])
+	assert.Equal(t, "Old", actual[0].Items[0].Name)
+}
@@ -0,0 +1,56 @@
+package protect
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Reset zeroes the fields of the value v points to except those protected for the tag.
+// See Protector.Reset for details.
+func Reset(tag string, v interface{}) error {
+	return DefaultProtector.Reset(tag, v)
+}
+
+// Reset zeroes the fields of the value v points to except those protected for the tag,
+// so that pooled request-scoped entities can be recycled without leaking client data
+// while keeping server-managed fields.
+// Structs and pointers to structs are reset field by field to keep nested protected fields,
+// and other writable values, including slices and maps, are zeroed as a whole.
+func (p *Protector) Reset(tag string, v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Ptr {
+		return fmt.Errorf("%w, got %T: pass the address of the value (e.g. &v)", ErrDstNotPointer, v)
+	}
+	if val.IsNil() {
+		return fmt.Errorf("v must not be nil pointer")
+	}
+
+	p.resetValue(tag, val.Elem(), nil, map[uintptr]bool{})
+	return nil
+}
+
+// resetValue zeroes v except fields protected for the tag.
+// promoted holds tags for promoted fields attached by the embedding struct,
+// and visited holds addresses of pointers already reset to stop at cycles.
+func (p *Protector) resetValue(tag string, v reflect.Value, promoted map[string]string, visited map[uintptr]bool) {
+	switch {
+	case v.Kind() == reflect.Struct && !p.IsPrimitiveStruct(v.Type()):
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || isProtected(p.promotedProtectionTagValue(field, promoted), tag) {
+				continue
+			}
+			p.resetValue(tag, v.Field(i), p.embeddedPromotedTagValues(field), visited)
+		}
+	case v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct && !p.IsPrimitiveStruct(v.Elem().Type()):
+		if visited[v.Pointer()] {
+			return
+		}
+		visited[v.Pointer()] = true
+		p.resetValue(tag, v.Elem(), promoted, visited)
+	default:
+		if v.CanSet() {
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReset(t *testing.T) {
+	v := NestedStruct{
+		ID:     "1",
+		Parent: &SimpleStruct{ID: "p1", Code: "pc1", Name: "Parent"},
+		Child:  SimpleStruct{ID: "c1", Code: "cc1", Name: "Child"},
+	}
+	parent := v.Parent
+
+	err := Reset("update", &v)
+	assert.NoError(t, err)
+	assert.Equal(t, NestedStruct{
+		ID:     "1",
+		Parent: &SimpleStruct{ID: "p1", Code: "pc1"},
+		Child:  SimpleStruct{ID: "c1", Code: "cc1"},
+	}, v)
+	assert.Same(t, parent, v.Parent)
+
+	t.Run("collections are zeroed", func(t *testing.T) {
+		v := SliceHolder{Items: []SimpleStruct{{ID: "1"}}}
+		err := Reset("update", &v)
+		assert.NoError(t, err)
+		assert.Nil(t, v.Items)
+	})
+
+	t.Run("promoted fields", func(t *testing.T) {
+		v := PromotedTagStruct{AuditFields: AuditFields{ID: "1", CreatedAt: "now", Note: "note"}, Name: "name"}
+		err := Reset("update", &v)
+		assert.NoError(t, err)
+		assert.Equal(t, PromotedTagStruct{AuditFields: AuditFields{ID: "1", CreatedAt: "now"}}, v)
+	})
+
+	t.Run("without tags", func(t *testing.T) {
+		v := SimpleStruct{ID: "1", Code: "A", Name: "Name"}
+		err := Reset("", &v)
+		assert.NoError(t, err)
+		assert.Equal(t, SimpleStruct{}, v)
+	})
+
+	t.Run("not a pointer", func(t *testing.T) {
+		err := Reset("update", SimpleStruct{})
+		assert.ErrorIs(t, err, ErrDstNotPointer)
+	})
+}
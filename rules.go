@@ -0,0 +1,99 @@
+package protect
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// rule is a rule added with WithRule.
+type rule struct {
+	// requiredField is the name of the sibling field required to have requiredValue.
+	requiredField string
+	// requiredValue is the value requiredField must have.
+	requiredValue interface{}
+}
+
+// RuleError is returned when a copy changes a field against a rule added with WithRule.
+type RuleError struct {
+	// Path is the path of the changed field, e.g. "Status".
+	Path string
+	// RequiredField is the name of the field the rule depends on, e.g. "LockedBy".
+	RequiredField string
+	// RequiredValue is the value RequiredField must have to change the field.
+	RequiredValue interface{}
+	// Actual is the value of RequiredField in dst.
+	Actual interface{}
+}
+
+// Error returns the description of the error.
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("%s cannot be changed unless %s is %v, got %v", e.Path, e.RequiredField, e.RequiredValue, e.Actual)
+}
+
+// WithRule allows changing the field at the path only when its sibling field requiredField
+// has requiredValue in dst, e.g. WithRule("Status", "LockedBy", "") to forbid changing the status of locked entities.
+// The copy fails with *RuleError otherwise.
+// Rules are evaluated against dst before any field of the struct is copied,
+// and fields protected for the tag are never changed, so rules don't apply to them.
+// Elements of slices and maps are checked only when they are copied onto existing elements
+// (e.g. "match" mode), as there is nothing changed otherwise.
+// Note that dst may be partially updated when the copy fails; use CopyPure to avoid that.
+func WithRule(path, requiredField string, requiredValue interface{}) Option {
+	return func(s *copyState) {
+		if s.rules == nil {
+			s.rules = make(map[string][]rule)
+		}
+		s.rules[path] = append(s.rules[path], rule{requiredField: requiredField, requiredValue: requiredValue})
+	}
+}
+
+// checkRules checks rules for fields of the struct changed by copying src to dst.
+// promoted holds tags for promoted fields attached by the embedding struct.
+func (p *Protector) checkRules(s *copyState, src, dst reflect.Value, promoted map[string]string) error {
+	t := src.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldPath := joinPath(s.path, field.Name)
+		rules := s.rules[fieldPath]
+		if len(rules) == 0 || !field.IsExported() || isProtected(p.promotedProtectionTagValue(field, promoted), s.tag) {
+			continue
+		}
+		if reflect.DeepEqual(src.Field(i).Interface(), dst.Field(i).Interface()) {
+			continue
+		}
+
+		for _, r := range rules {
+			required := dst.FieldByName(r.requiredField)
+			if !required.IsValid() || !required.CanInterface() {
+				return fmt.Errorf("rule for %s: %s has no field %s", fieldPath, t, r.requiredField)
+			}
+			if !matchesValue(required, r.requiredValue) {
+				return &RuleError{
+					Path:          fieldPath,
+					RequiredField: r.requiredField,
+					RequiredValue: r.requiredValue,
+					Actual:        required.Interface(),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchesValue checks if v equals want, converting want to the type of v
+// so that untyped constants match named types (e.g. "" for `type UserID string`).
+// nil matches zero values of pointers, slices, maps and interfaces.
+func matchesValue(v reflect.Value, want interface{}) bool {
+	w := reflect.ValueOf(want)
+	if !w.IsValid() {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface, reflect.Func, reflect.Chan:
+			return v.IsNil()
+		}
+		return false
+	}
+	if w.Type() != v.Type() && w.Type().ConvertibleTo(v.Type()) && w.Kind() == v.Kind() {
+		w = w.Convert(v.Type())
+	}
+	return reflect.DeepEqual(v.Interface(), w.Interface())
+}
@@ -0,0 +1,76 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TicketOwner string
+
+type Ticket struct {
+	ID       string `protectfor:"update"`
+	Status   string
+	LockedBy TicketOwner
+	Lines    []TicketLine
+}
+
+type TicketLine struct {
+	Note     string
+	Approved *bool
+}
+
+func TestRule(t *testing.T) {
+	rule := WithRule("Status", "LockedBy", "")
+
+	t.Run("unlocked", func(t *testing.T) {
+		dst := Ticket{ID: "1", Status: "open"}
+		err := Copy("update", &Ticket{Status: "closed"}, &dst, rule)
+		assert.NoError(t, err)
+		assert.Equal(t, "closed", dst.Status)
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		dst := Ticket{ID: "1", Status: "open", LockedBy: "alice"}
+		err := Copy("update", &Ticket{Status: "closed"}, &dst, rule)
+		var ruleErr *RuleError
+		if assert.ErrorAs(t, err, &ruleErr) {
+			assert.Equal(t, "Status", ruleErr.Path)
+			assert.Equal(t, "LockedBy", ruleErr.RequiredField)
+			assert.Equal(t, TicketOwner("alice"), ruleErr.Actual)
+		}
+		assert.Equal(t, "open", dst.Status)
+	})
+
+	t.Run("unchanged field", func(t *testing.T) {
+		// Unlocking in the same copy is evaluated against the locked dst, but the status isn't changed
+		dst := Ticket{ID: "1", Status: "open", LockedBy: "alice"}
+		err := Copy("update", &Ticket{Status: "open"}, &dst, rule)
+		assert.NoError(t, err)
+		assert.Equal(t, TicketOwner(""), dst.LockedBy)
+	})
+
+	t.Run("elements", func(t *testing.T) {
+		dst := Ticket{Lines: []TicketLine{{Note: "old", Approved: new(bool)}}}
+		src := Ticket{Lines: []TicketLine{{Note: "new"}}}
+		err := Copy("update", &src, &dst, WithRule("Lines[].Note", "Approved", nil), WithSliceOption("Lines", "match"))
+		assert.ErrorAs(t, err, new(*RuleError))
+	})
+
+	t.Run("promoted fields", func(t *testing.T) {
+		rule := WithRule("AuditFields.ID", "Note", "")
+		dst := PromotedTagStruct{AuditFields: AuditFields{ID: "1", Note: "locked"}}
+		err := Copy("update", &PromotedTagStruct{AuditFields: AuditFields{ID: "2", Note: "locked"}}, &dst, rule)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", dst.ID)
+
+		err = Copy("other", &PromotedTagStruct{AuditFields: AuditFields{ID: "2", Note: "locked"}}, &dst, rule)
+		assert.ErrorAs(t, err, new(*RuleError))
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		dst := Ticket{Status: "open"}
+		err := Copy("update", &Ticket{Status: "closed"}, &dst, WithRule("Status", "Missing", ""))
+		assert.ErrorContains(t, err, "no field Missing")
+	})
+}
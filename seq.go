@@ -0,0 +1,38 @@
+package protect
+
+import (
+	"iter"
+)
+
+// CloneSeq returns an iterator yielding deep copies of values from seq with DefaultProtector,
+// so that values from iterator pipelines can be retained or modified without aliasing the source.
+// The iterator yields the clone and the error cloning it for each value,
+// and continues with the next value after errors as long as the consumer does.
+func CloneSeq[T any](seq iter.Seq[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for v := range seq {
+			if !yield(cloneT(DefaultProtector, v)) {
+				return
+			}
+		}
+	}
+}
+
+// CopySeq returns an iterator copying each value from seq onto its destination with DefaultProtector,
+// applying protection by the tag mid-stream.
+// target returns the destination for the value, e.g. the existing entity looked up by the ID in the value.
+// The iterator yields the destination and the error of the lookup or the copy for each value,
+// and continues with the next value after errors as long as the consumer does.
+func CopySeq[T any](tag string, seq iter.Seq[T], target func(v *T) (*T, error)) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		for v := range seq {
+			dst, err := target(&v)
+			if err == nil {
+				err = DefaultProtector.Copy(tag, &v, dst)
+			}
+			if !yield(dst, err) {
+				return
+			}
+		}
+	}
+}
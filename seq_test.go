@@ -0,0 +1,52 @@
+package protect
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneSeq(t *testing.T) {
+	src := []*SimpleStruct{{ID: "1", Name: "First"}, {ID: "2", Name: "Second"}}
+
+	var clones []*SimpleStruct
+	for clone, err := range CloneSeq(slices.Values(src)) {
+		assert.NoError(t, err)
+		clones = append(clones, clone)
+	}
+	assert.Equal(t, src, clones)
+	assert.NotSame(t, src[0], clones[0])
+
+	// Stops when the consumer stops
+	for clone := range CloneSeq(slices.Values(src)) {
+		assert.Equal(t, "1", clone.ID)
+		break
+	}
+}
+
+func TestCopySeq(t *testing.T) {
+	stored := map[string]*SimpleStruct{
+		"1": {ID: "1", Name: "First"},
+	}
+	notFound := errors.New("not found")
+	target := func(v *SimpleStruct) (*SimpleStruct, error) {
+		if dst, ok := stored[v.Name]; ok {
+			return dst, nil
+		}
+		return nil, notFound
+	}
+	patches := []SimpleStruct{{ID: "X", Name: "1"}, {ID: "Y", Name: "2"}}
+
+	var errs []error
+	for dst, err := range CopySeq("create", slices.Values(patches), target) {
+		errs = append(errs, err)
+		if err == nil {
+			assert.Same(t, stored["1"], dst)
+		}
+	}
+	assert.Equal(t, []error{nil, notFound}, errs)
+	// ID is protected for "create"
+	assert.Equal(t, &SimpleStruct{ID: "1", Name: "1"}, stored["1"])
+}
@@ -0,0 +1,119 @@
+package protect
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// stampTagName is the tag name to specify fields stamped with the current time or new IDs by a copy.
+// The tag value is a comma-separated list of tags like protection tags, e.g. `protectstamp:"create,update"`.
+// When Copy or CopySlice with one of the tags writes a struct or creates a new one,
+// time.Time and *time.Time fields with the tag are set to the current time,
+// and empty string fields with the tag are set to new IDs if an IDGenerator is set.
+// Structs the copy never touched, such as extra elements kept by the "longer" slice option, are not stamped.
+const stampTagName = "protectstamp"
+
+// Clock provides the current time to stamp fields.
+type Clock interface {
+	Now() time.Time
+}
+
+// IDGenerator generates IDs to stamp fields, e.g. UUIDv7 or ULID.
+type IDGenerator interface {
+	NewID() string
+}
+
+// clockValue wraps Clock to store in atomic.Value, which requires values of the same concrete type.
+type clockValue struct {
+	Clock
+}
+
+// idGeneratorValue wraps IDGenerator to store in atomic.Value.
+type idGeneratorValue struct {
+	IDGenerator
+}
+
+// SetClock sets the Clock to stamp time fields, e.g. to use fixed time in tests.
+// time.Now is used if no Clock is set or clock is nil.
+// WithClock takes precedence over this.
+func (p *Protector) SetClock(clock Clock) {
+	p.clock.Store(clockValue{clock})
+}
+
+// SetIDGenerator sets the IDGenerator to stamp string fields.
+// String fields with `protectstamp` tags are stamped only when they are empty after the copy,
+// that is, for newly created values and elements, and only if an IDGenerator is set.
+func (p *Protector) SetIDGenerator(generator IDGenerator) {
+	p.idGenerator.Store(idGeneratorValue{generator})
+}
+
+// getClock returns the Clock set with SetClock, or nil.
+func (p *Protector) getClock() Clock {
+	v, _ := p.clock.Load().(clockValue)
+	return v.Clock
+}
+
+// getIDGenerator returns the IDGenerator set with SetIDGenerator, or nil.
+func (p *Protector) getIDGenerator() IDGenerator {
+	v, _ := p.idGenerator.Load().(idGeneratorValue)
+	return v.IDGenerator
+}
+
+// stampFields caches indexes of exported fields with stamp tags by struct types.
+var stampFields sync.Map
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// stampFieldIndexes returns the indexes of exported fields with stamp tags in the struct type t.
+func stampFieldIndexes(t reflect.Type) []int {
+	if cached, ok := stampFields.Load(t); ok {
+		return cached.([]int)
+	}
+	var indexes []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.IsExported() && field.Tag.Get(stampTagName) != "" {
+			indexes = append(indexes, i)
+		}
+	}
+	stampFields.Store(t, indexes)
+	return indexes
+}
+
+// stampNow returns the time to stamp fields, which is the same throughout the operation.
+func (s *copyState) stampNow() time.Time {
+	if s.stampTime.IsZero() {
+		s.stampTime = s.now()
+	}
+	return s.stampTime
+}
+
+// stampStruct sets fields of the struct v with stamp tags for the tag of the operation
+// to the current time or new IDs.
+// It is called for each struct the copy writes or creates,
+// so values the copy never touched, such as values for keys only in dst of "patch" maps,
+// are not stamped.
+func (p *Protector) stampStruct(s *copyState, v reflect.Value) {
+	if s.tag == "" || s.cloning {
+		return
+	}
+	for _, i := range stampFieldIndexes(v.Type()) {
+		field := v.Type().Field(i)
+		fieldV := v.Field(i)
+		if !isProtected(field.Tag.Get(stampTagName), s.tag) || !fieldV.CanSet() {
+			continue
+		}
+		switch {
+		case field.Type == timeType:
+			fieldV.Set(reflect.ValueOf(s.stampNow()))
+		case field.Type == reflect.PointerTo(timeType):
+			t := s.stampNow()
+			fieldV.Set(reflect.ValueOf(&t))
+		case field.Type.Kind() == reflect.String:
+			if generator := p.getIDGenerator(); generator != nil && fieldV.Len() == 0 {
+				fieldV.SetString(generator.NewID())
+			}
+		}
+	}
+}
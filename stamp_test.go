@@ -0,0 +1,174 @@
+package protect
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type StampedItem struct {
+	Name      string
+	UpdatedAt *time.Time `protectstamp:"update"`
+}
+
+type StampedStruct struct {
+	Name      string
+	CreatedAt time.Time `protectfor:"update" protectstamp:"create"`
+	UpdatedAt time.Time `protectstamp:"create,update"`
+	Items     []StampedItem
+	ByKey     map[string]StampedItem
+	Parent    *StampedStruct
+}
+
+func TestStamp(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+	earlier := now.Add(-time.Hour)
+
+	t.Run("create", func(t *testing.T) {
+		dst := StampedStruct{}
+		err := Copy("create", &StampedStruct{Name: "new"}, &dst, clock)
+		assert.NoError(t, err)
+		assert.Equal(t, now, dst.CreatedAt)
+		assert.Equal(t, now, dst.UpdatedAt)
+	})
+
+	t.Run("update stamps nested fields", func(t *testing.T) {
+		dst := StampedStruct{CreatedAt: earlier, UpdatedAt: earlier}
+		src := StampedStruct{
+			Name:  "new",
+			Items: []StampedItem{{Name: "item"}},
+			ByKey: map[string]StampedItem{"k": {Name: "item"}},
+		}
+		src.Parent = &StampedStruct{Name: "parent"}
+
+		err := Copy("update", &src, &dst, clock)
+		assert.NoError(t, err)
+		assert.Equal(t, earlier, dst.CreatedAt)
+		assert.Equal(t, now, dst.UpdatedAt)
+		assert.Equal(t, now, *dst.Items[0].UpdatedAt)
+		assert.Equal(t, now, *dst.ByKey["k"].UpdatedAt)
+		assert.Equal(t, now, dst.Parent.UpdatedAt)
+	})
+
+	t.Run("other tags", func(t *testing.T) {
+		dst := StampedStruct{}
+		err := Copy("other", &StampedStruct{}, &dst, clock)
+		assert.NoError(t, err)
+		assert.True(t, dst.UpdatedAt.IsZero())
+
+		// Clone never stamps
+		clone := Clone(&StampedStruct{}).(*StampedStruct)
+		assert.True(t, clone.UpdatedAt.IsZero())
+	})
+
+	t.Run("CopySlice", func(t *testing.T) {
+		dst := []StampedItem{}
+		err := CopySlice("update", []StampedItem{{Name: "item"}}, &dst, "overwrite", clock)
+		assert.NoError(t, err)
+		assert.Equal(t, now, *dst[0].UpdatedAt)
+	})
+
+	t.Run("shallow and protected fields", func(t *testing.T) {
+		type Holder struct {
+			Meta     *StampedItem `protectopt:"shallow"`
+			Archived *StampedItem `protectfor:"update"`
+		}
+		meta := &StampedItem{Name: "meta"}
+		archived := &StampedItem{Name: "archived"}
+		dst := Holder{Archived: archived}
+		err := Copy("update", &Holder{Meta: meta}, &dst, clock)
+		assert.NoError(t, err)
+		assert.Same(t, meta, dst.Meta)
+		assert.Nil(t, meta.UpdatedAt)
+		assert.Nil(t, archived.UpdatedAt)
+	})
+
+	t.Run("values the copy never touched", func(t *testing.T) {
+		dst := StampedStruct{
+			Items: []StampedItem{{Name: "old"}, {Name: "kept", UpdatedAt: &earlier}},
+			ByKey: map[string]StampedItem{"k": {Name: "old"}, "kept": {Name: "kept", UpdatedAt: &earlier}},
+		}
+		src := StampedStruct{
+			Items: []StampedItem{{Name: "new"}},
+			ByKey: map[string]StampedItem{"k": {Name: "new"}},
+		}
+		err := Copy("update", &src, &dst, clock, WithSliceOption("Items", "longer"), WithMapOption("ByKey", "patch"))
+		assert.NoError(t, err)
+		assert.Equal(t, now, *dst.Items[0].UpdatedAt)
+		assert.Equal(t, earlier, *dst.Items[1].UpdatedAt)
+		assert.Equal(t, now, *dst.ByKey["k"].UpdatedAt)
+		assert.Equal(t, earlier, *dst.ByKey["kept"].UpdatedAt)
+	})
+
+	t.Run("failed copy", func(t *testing.T) {
+		dst := StampedStruct{}
+		err := Copy("update", &StampedStruct{}, &dst, clock, WithVersion("Name"))
+		assert.Error(t, err)
+		assert.True(t, dst.UpdatedAt.IsZero())
+	})
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+type sequentialIDs struct {
+	next int
+}
+
+func (g *sequentialIDs) NewID() string {
+	g.next++
+	return fmt.Sprintf("id-%d", g.next)
+}
+
+type StampedElement struct {
+	ID   string `protectfor:"update" protectstamp:"create,update"`
+	Name string
+}
+
+type StampedList struct {
+	ID        string    `protectfor:"update" protectstamp:"create"`
+	UpdatedAt time.Time `protectstamp:"create,update"`
+	Elements  []StampedElement
+}
+
+func TestStampWithProtectorClockAndIDGenerator(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	p := NewProtector("protectfor", "protectopt")
+	p.SetClock(fixedClock(now))
+	p.SetIDGenerator(&sequentialIDs{})
+
+	dst := StampedList{}
+	err := p.Copy("create", &StampedList{Elements: []StampedElement{{Name: "first"}}}, &dst)
+	assert.NoError(t, err)
+	// Structs are stamped when they are written, after the values in them
+	assert.Equal(t, StampedList{
+		ID:        "id-2",
+		UpdatedAt: now,
+		Elements:  []StampedElement{{ID: "id-1", Name: "first"}},
+	}, dst)
+
+	// Only new elements get IDs
+	src := StampedList{Elements: []StampedElement{{Name: "first"}, {Name: "second"}}}
+	err = p.Copy("update", &src, &dst, WithSliceOption("Elements", "match"))
+	assert.NoError(t, err)
+	assert.Equal(t, "id-2", dst.ID)
+	assert.Equal(t, []StampedElement{{ID: "id-1", Name: "first"}, {ID: "id-3", Name: "second"}}, dst.Elements)
+
+	// WithClock takes precedence
+	later := now.Add(time.Hour)
+	err = p.Copy("update", &src, &dst, WithClock(func() time.Time { return later }))
+	assert.NoError(t, err)
+	assert.Equal(t, later, dst.UpdatedAt)
+
+	// Without IDGenerator, string fields are left as they are
+	dst = StampedList{}
+	err = Copy("create", &StampedList{}, &dst)
+	assert.NoError(t, err)
+	assert.Empty(t, dst.ID)
+}
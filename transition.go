@@ -0,0 +1,108 @@
+package protect
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Transitions is a table of allowed transitions of a field,
+// mapping each value to the values the field may change to from it.
+// Values are compared after converting them to the type of the field,
+// so untyped constants can be used for named types:
+//
+//	protect.Transitions{
+//		"draft":     {"published", "archived"},
+//		"published": {"archived"},
+//	}
+type Transitions map[interface{}][]interface{}
+
+// TransitionError is returned when a copy changes a field against the Transitions registered for it.
+type TransitionError struct {
+	// Path is the path of the field, e.g. "Status".
+	Path string
+	// From is the value of the field in dst.
+	From interface{}
+	// To is the value of the field in src.
+	To interface{}
+}
+
+// Error returns the description of the error.
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("%s cannot change from %v to %v", e.Path, e.From, e.To)
+}
+
+// transitionKey is the key of Transitions registered with RegisterTransitions.
+type transitionKey struct {
+	typ   reflect.Type
+	field string
+}
+
+// RegisterTransitions registers the allowed transitions of the field of the struct type of v to DefaultProtector.
+// See Protector.RegisterTransitions for details.
+func RegisterTransitions(v interface{}, field string, transitions Transitions) {
+	DefaultProtector.RegisterTransitions(v, field, transitions)
+}
+
+// RegisterTransitions registers the allowed transitions of the field of the struct type of v,
+// e.g. RegisterTransitions(&Article{}, "Status", transitions).
+// Copies changing the field to a value not allowed from the value in dst fail with *TransitionError.
+// Keeping the value is always allowed, and fields protected for the tag are never changed.
+// Copies without tags and clones are not checked, as they never apply changes from clients.
+// Non-zero values not in the table cannot change to anything,
+// and zero values not in the table (e.g. in new values) can change to any value;
+// add the zero value to the table to restrict initial values.
+// Registering transitions for the same field replaces the previous ones.
+// It panics if the type has no such field.
+func (p *Protector) RegisterTransitions(v interface{}, field string, transitions Transitions) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("protect: transitions must be registered for structs, got %T", v))
+	}
+	if _, ok := t.FieldByName(field); !ok {
+		panic(fmt.Sprintf("protect: %s has no field %s", t, field))
+	}
+
+	p.transitions.Store(transitionKey{typ: t, field: field}, transitions)
+	p.hasTransitions.Store(true)
+}
+
+// checkTransitions checks transitions of fields of the struct changed by copying src to dst.
+// promoted holds tags for promoted fields attached by the embedding struct.
+func (p *Protector) checkTransitions(s *copyState, src, dst reflect.Value, promoted map[string]string) error {
+	t := src.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		v, ok := p.transitions.Load(transitionKey{typ: t, field: field.Name})
+		if !ok || isProtected(p.promotedProtectionTagValue(field, promoted), s.tag) {
+			continue
+		}
+		from, to := dst.Field(i), src.Field(i)
+		if reflect.DeepEqual(from.Interface(), to.Interface()) {
+			continue
+		}
+
+		// Zero values not in the table may change to any value, e.g. on creation
+		allowed, known := false, false
+		for k, targets := range v.(Transitions) {
+			if matchesValue(from, k) {
+				known = true
+				allowed = containsValue(targets, to)
+				break
+			}
+		}
+		if !allowed && (known || !from.IsZero()) {
+			return &TransitionError{
+				Path: joinPath(s.path, field.Name),
+				From: from.Interface(),
+				To:   to.Interface(),
+			}
+		}
+	}
+	return nil
+}
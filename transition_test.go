@@ -0,0 +1,108 @@
+package protect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ArticleStatus string
+
+type Article struct {
+	ID     string `protectfor:"update"`
+	Status ArticleStatus
+	Title  string
+}
+
+func TestTransitions(t *testing.T) {
+	p := NewProtector("protectfor", "protectopt")
+	p.RegisterTransitions(&Article{}, "Status", Transitions{
+		"draft":     {"published", "archived"},
+		"published": {"archived"},
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		dst := Article{ID: "1", Status: "draft"}
+		err := p.Copy("update", &Article{Status: "published", Title: "New"}, &dst)
+		assert.NoError(t, err)
+		assert.Equal(t, Article{ID: "1", Status: "published", Title: "New"}, dst)
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		dst := Article{ID: "1", Status: "published"}
+		err := p.Copy("update", &Article{Status: "draft", Title: "New"}, &dst)
+		var transitionErr *TransitionError
+		if assert.ErrorAs(t, err, &transitionErr) {
+			assert.Equal(t, "Status", transitionErr.Path)
+			assert.Equal(t, ArticleStatus("published"), transitionErr.From)
+			assert.Equal(t, ArticleStatus("draft"), transitionErr.To)
+		}
+		assert.Equal(t, ArticleStatus("published"), dst.Status)
+		assert.Equal(t, "", dst.Title)
+	})
+
+	t.Run("final state", func(t *testing.T) {
+		dst := Article{Status: "archived"}
+		err := p.Copy("update", &Article{Status: "draft"}, &dst)
+		assert.ErrorAs(t, err, new(*TransitionError))
+	})
+
+	t.Run("unchanged and new values", func(t *testing.T) {
+		dst := Article{Status: "archived"}
+		assert.NoError(t, p.Copy("update", &Article{Status: "archived", Title: "New"}, &dst))
+
+		var created Article
+		assert.NoError(t, p.Copy("create", &Article{Status: "published"}, &created))
+		assert.Equal(t, ArticleStatus("published"), created.Status)
+	})
+
+	t.Run("clones with the zero value in the table", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		p.RegisterTransitions(&Article{}, "Status", Transitions{
+			"":          {"draft"},
+			"draft":     {"published"},
+			"published": {"archived"},
+		})
+
+		src := Article{ID: "1", Status: "published", Title: "Title"}
+		assert.Equal(t, &src, p.Clone(&src))
+		assert.Equal(t, &Article{Status: "published", Title: "Title"}, p.CloneWritable("update", &src))
+
+		result, err := p.CopyPure("update", &Article{Status: "archived"}, &src)
+		assert.NoError(t, err)
+		assert.Equal(t, &Article{ID: "1", Status: "archived"}, result)
+
+		_, err = p.CopyPure("create", &Article{Status: "published"}, &Article{})
+		assert.ErrorAs(t, err, new(*TransitionError))
+	})
+
+	t.Run("unexported fields", func(t *testing.T) {
+		type lockedArticle struct {
+			Title  string
+			status string
+		}
+		p := NewProtector("protectfor", "protectopt")
+		p.RegisterTransitions(&lockedArticle{}, "status", Transitions{"draft": {"published"}})
+
+		dst := lockedArticle{status: "draft"}
+		assert.NoError(t, p.Copy("update", &lockedArticle{Title: "New", status: "archived"}, &dst))
+		assert.Equal(t, lockedArticle{Title: "New", status: "draft"}, dst)
+	})
+
+	t.Run("promoted fields", func(t *testing.T) {
+		p := NewProtector("protectfor", "protectopt")
+		p.RegisterTransitions(&AuditFields{}, "ID", Transitions{"1": {"2"}})
+
+		dst := PromotedTagStruct{AuditFields: AuditFields{ID: "3"}}
+		assert.NoError(t, p.Copy("update", &PromotedTagStruct{AuditFields: AuditFields{ID: "4"}}, &dst))
+		assert.Equal(t, "3", dst.ID)
+
+		err := p.Copy("other", &PromotedTagStruct{AuditFields: AuditFields{ID: "4"}}, &dst)
+		assert.ErrorAs(t, err, new(*TransitionError))
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		assert.Panics(t, func() { p.RegisterTransitions(&Article{}, "Missing", nil) })
+		assert.Panics(t, func() { p.RegisterTransitions("", "Status", nil) })
+	})
+}